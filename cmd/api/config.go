@@ -0,0 +1,561 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/alert"
+	"github.com/agkmw/reddit-clone/internal/platform/conf"
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
+)
+
+type config struct {
+	file  string
+	dump  bool
+	watch time.Duration
+
+	port        int
+	environment string
+	log         struct {
+		level  string
+		format string
+		redact string
+		sample string
+		file   struct {
+			path       string
+			maxSize    int
+			maxAge     time.Duration
+			maxBackups int
+			compress   bool
+		}
+	}
+	alert struct {
+		webhook   string
+		file      string
+		dedup     time.Duration
+		flush     time.Duration
+		perMinute int
+		smtp      struct {
+			addr     string
+			from     string
+			to       string
+			username string
+			password string
+		}
+	}
+	debug struct {
+		host string
+	}
+	tracing struct {
+		endpoint string
+	}
+	shutdown struct {
+		drain   time.Duration
+		timeout time.Duration
+	}
+	admin struct {
+		token string
+	}
+	proxy struct {
+		trusted string
+	}
+	idempotency struct {
+		ttl time.Duration
+	}
+	request struct {
+		maxHeaderBytes int
+		maxBodyBytes   int64
+	}
+	security struct {
+		hstsMaxAge time.Duration
+	}
+	compression struct {
+		enabled bool
+		minSize int
+	}
+	cors struct {
+		origins     string
+		credentials bool
+		maxAge      time.Duration
+	}
+	flags struct {
+		refresh time.Duration
+	}
+	problem struct {
+		always  bool
+		typeURI string
+	}
+	limiter struct {
+		enabled bool
+		rps     float64
+		burst   int
+		store   string
+		routes  string
+	}
+	db struct {
+		dsn string
+
+		maxConns     int
+		minConns     int
+		minIdleConns int
+
+		maxConnIdleTime time.Duration
+		maxConnLifeTime time.Duration
+
+		healthCheckPeriod time.Duration
+	}
+}
+
+func confConfig(getenv func(string) string) conf.Config {
+	return conf.Config{
+		Prefix:   "NEXUS",
+		FileFlag: "config",
+		Required: []string{"db-dsn"},
+		Getenv:   getenv,
+	}
+}
+
+// loadConfig builds the flag set and layers defaults, the config file, the
+// environment and args onto it. It is called again on every reload.
+func loadConfig(
+	args []string,
+	getenv func(string) string,
+	stderr io.Writer,
+) (config, *flag.FlagSet, conf.Sources, error) {
+	var cfg config
+
+	fs := flag.NewFlagSet("reddit-clone", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	fs.StringVar(
+		&cfg.file,
+		"config",
+		"",
+		"Path to a JSON, YAML or TOML config file keyed by flag name",
+	)
+	fs.BoolVar(
+		&cfg.dump,
+		"dump-config",
+		false,
+		"Print the effective configuration with secrets redacted and exit",
+	)
+	fs.DurationVar(
+		&cfg.watch,
+		"config-watch",
+		10*time.Second,
+		"How often to check the config file for changes to runtime settings (0 disables, SIGHUP always reloads)",
+	)
+
+	fs.StringVar(
+		&cfg.log.level,
+		"log-level",
+		"info",
+		"Minimum log level (debug|info|warn|error)",
+	)
+	fs.StringVar(
+		&cfg.log.format,
+		"log-format",
+		"",
+		"Log format (json|console); defaults to console in development and json elsewhere",
+	)
+	fs.StringVar(
+		&cfg.log.file.path,
+		"log-file",
+		"",
+		"Write logs to this file instead of stdout, with rotation",
+	)
+	fs.IntVar(
+		&cfg.log.file.maxSize,
+		"log-file-max-size",
+		100,
+		"Rotate the log file when it reaches this many megabytes (0 disables)",
+	)
+	fs.DurationVar(
+		&cfg.log.file.maxAge,
+		"log-file-max-age",
+		24*time.Hour,
+		"Rotate the log file when it is this old (0 disables)",
+	)
+	fs.IntVar(
+		&cfg.log.file.maxBackups,
+		"log-file-max-backups",
+		7,
+		"Number of rotated log files to keep (0 keeps all)",
+	)
+	fs.BoolVar(
+		&cfg.log.file.compress,
+		"log-file-compress",
+		true,
+		"Gzip rotated log files",
+	)
+	fs.StringVar(
+		&cfg.log.redact,
+		"log-redact",
+		"",
+		"Comma separated attribute keys to redact in addition to the built-in credential keys",
+	)
+	fs.StringVar(
+		&cfg.log.sample,
+		"log-sample",
+		"",
+		`Per-message log sampling as "message=first/thereafter[/tick]" entries separated by ";"`,
+	)
+
+	fs.StringVar(
+		&cfg.alert.webhook,
+		"alert-webhook",
+		"",
+		"URL that error alerts are posted to as JSON",
+	)
+	fs.StringVar(
+		&cfg.alert.file,
+		"alert-file",
+		"",
+		"File that error alerts are appended to as JSON lines",
+	)
+	fs.StringVar(
+		&cfg.alert.smtp.addr,
+		"alert-smtp-addr",
+		"",
+		"SMTP server (host:port) used to mail error alerts",
+	)
+	fs.StringVar(
+		&cfg.alert.smtp.from,
+		"alert-smtp-from",
+		"",
+		"Sender address for alert emails",
+	)
+	fs.StringVar(
+		&cfg.alert.smtp.to,
+		"alert-smtp-to",
+		"",
+		"Comma separated recipients for alert emails",
+	)
+	fs.StringVar(
+		&cfg.alert.smtp.username,
+		"alert-smtp-username",
+		"",
+		"SMTP username",
+	)
+	fs.StringVar(
+		&cfg.alert.smtp.password,
+		"alert-smtp-password",
+		"",
+		"SMTP password",
+	)
+	fs.DurationVar(
+		&cfg.alert.dedup,
+		"alert-dedup",
+		10*time.Minute,
+		"Window in which repeated alerts with the same fingerprint are suppressed",
+	)
+	fs.DurationVar(
+		&cfg.alert.flush,
+		"alert-flush",
+		10*time.Second,
+		"How long alerts are batched before delivery",
+	)
+	fs.IntVar(
+		&cfg.alert.perMinute,
+		"alert-per-minute",
+		20,
+		"Maximum alerts delivered per minute",
+	)
+
+	fs.IntVar(
+		&cfg.port,
+		"port",
+		4000,
+		"Application server port",
+	)
+	fs.StringVar(
+		&cfg.environment,
+		"environment",
+		"development",
+		"Environment (development|staging|production)",
+	)
+
+	fs.StringVar(
+		&cfg.debug.host,
+		"debug-host",
+		"localhost:4010",
+		"Debug server address (metrics, pprof, expvar); bound to localhost by default, never expose it publicly",
+	)
+
+	fs.DurationVar(
+		&cfg.shutdown.drain,
+		"shutdown-drain",
+		5*time.Second,
+		"Time to report not-ready before the server stops accepting connections",
+	)
+	fs.DurationVar(
+		&cfg.shutdown.timeout,
+		"shutdown-timeout",
+		30*time.Second,
+		"Time to wait for in-flight requests and background tasks to stop",
+	)
+
+	fs.StringVar(
+		&cfg.tracing.endpoint,
+		"otlp-endpoint",
+		"",
+		"OTLP/HTTP collector endpoint for trace export, e.g. http://localhost:4318 (disabled when empty)",
+	)
+
+	fs.StringVar(
+		&cfg.proxy.trusted,
+		"trusted-proxies",
+		"",
+		"Comma separated CIDRs of proxies whose forwarding headers are trusted for the client IP",
+	)
+
+	fs.DurationVar(
+		&cfg.idempotency.ttl,
+		"idempotency-ttl",
+		24*time.Hour,
+		"How long responses to requests with an Idempotency-Key are kept for replay",
+	)
+
+	fs.IntVar(
+		&cfg.request.maxHeaderBytes,
+		"max-header-bytes",
+		16*1024,
+		"Largest request header block in bytes; larger ones are refused with a 431",
+	)
+	fs.Int64Var(
+		&cfg.request.maxBodyBytes,
+		"max-body-bytes",
+		1024*1024,
+		"Largest request body in bytes on routes without a limit of their own; larger ones are refused with a 413",
+	)
+
+	fs.DurationVar(
+		&cfg.security.hstsMaxAge,
+		"hsts-max-age",
+		365*24*time.Hour,
+		"How long browsers must keep to HTTPS for this host (Strict-Transport-Security is not sent when 0)",
+	)
+
+	fs.BoolVar(
+		&cfg.compression.enabled,
+		"compression",
+		true,
+		"Compress responses with gzip or deflate when the client accepts it",
+	)
+	fs.IntVar(
+		&cfg.compression.minSize,
+		"compression-min-size",
+		1024,
+		"Smallest response body in bytes that is compressed",
+	)
+
+	fs.StringVar(
+		&cfg.cors.origins,
+		"cors-origins",
+		"",
+		"Comma separated origins allowed to call the API from a browser, e.g. https://*.example.com (CORS is disabled when empty)",
+	)
+	fs.BoolVar(
+		&cfg.cors.credentials,
+		"cors-credentials",
+		false,
+		"Allow cross-origin requests to send credentials (cannot be combined with a cors-origins of *)",
+	)
+	fs.DurationVar(
+		&cfg.cors.maxAge,
+		"cors-max-age",
+		10*time.Minute,
+		"How long browsers may cache preflight responses",
+	)
+
+	fs.StringVar(
+		&cfg.admin.token,
+		"admin-token",
+		"",
+		"Bearer token for the admin API (admin routes are disabled when empty)",
+	)
+	fs.DurationVar(
+		&cfg.flags.refresh,
+		"flags-refresh",
+		30*time.Second,
+		"How often feature flags are reloaded from the database",
+	)
+
+	fs.BoolVar(
+		&cfg.problem.always,
+		"problem-details",
+		false,
+		"Always respond with RFC 9457 problem details instead of negotiating via Accept",
+	)
+	fs.StringVar(
+		&cfg.problem.typeURI,
+		"problem-type-uri",
+		"/problems/",
+		"Base URI for problem details type identifiers",
+	)
+
+	fs.Float64Var(
+		&cfg.limiter.rps,
+		"limiter-rps",
+		2,
+		"Rate limiter maximum requests per second",
+	)
+	fs.IntVar(
+		&cfg.limiter.burst,
+		"limiter-burst",
+		4,
+		"Rate limiter maximum burst",
+	)
+	fs.BoolVar(
+		&cfg.limiter.enabled,
+		"limiter-enabled",
+		true,
+		"Enable rate limiter",
+	)
+	fs.StringVar(
+		&cfg.limiter.store,
+		"limiter-store",
+		"memory",
+		"Where rate limit state is kept: memory (per replica) or postgres (shared)",
+	)
+	fs.StringVar(
+		&cfg.limiter.routes,
+		"limiter-routes",
+		"",
+		"JSON file of per-route limits by user tier, replacing the built-in table",
+	)
+
+	fs.StringVar(
+		&cfg.db.dsn,
+		"db-dsn",
+		"",
+		"PostgreSQL DSN",
+	)
+	fs.IntVar(
+		&cfg.db.maxConns,
+		"db-max-conns",
+		25,
+		"PostgreSQL max open connections",
+	)
+	fs.IntVar(
+		&cfg.db.minConns,
+		"db-min-conns",
+		5,
+		"PostgreSQL min open connections",
+	)
+	fs.IntVar(
+		&cfg.db.minIdleConns,
+		"db-min-idle-conns",
+		25,
+		"PostgreSQL min idle connections",
+	)
+
+	fs.DurationVar(
+		&cfg.db.maxConnIdleTime,
+		"db-max-idle-time",
+		15*time.Minute,
+		"PostgeSQL max connection idle time",
+	)
+	fs.DurationVar(
+		&cfg.db.maxConnLifeTime,
+		"db-max-life-time",
+		2*time.Hour,
+		"PostgeSQL max connection life time",
+	)
+	fs.DurationVar(
+		&cfg.db.healthCheckPeriod,
+		"db-heathz-period",
+		time.Minute,
+		"PostgeSQL health check period",
+	)
+
+	sources, err := conf.Parse(fs, args, confConfig(getenv))
+	if err != nil {
+		return config{}, nil, nil, err
+	}
+
+	return cfg, fs, sources, nil
+}
+
+// splitList splits a comma separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// isTerminal reports whether w is a character device, so that colors are
+// only written to an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func alertSinks(cfg config) ([]alert.Sink, error) {
+	var sinks []alert.Sink
+
+	if cfg.alert.webhook != "" {
+		sinks = append(sinks, alert.NewWebhook(cfg.alert.webhook))
+	}
+
+	if cfg.alert.file != "" {
+		sinks = append(sinks, alert.NewFile(cfg.alert.file))
+	}
+
+	if cfg.alert.smtp.addr != "" {
+		if cfg.alert.smtp.from == "" || cfg.alert.smtp.to == "" {
+			return nil, errors.New("alert-smtp-addr requires alert-smtp-from and alert-smtp-to")
+		}
+
+		sinks = append(sinks, alert.NewSMTP(alert.SMTPConfig{
+			Addr:     cfg.alert.smtp.addr,
+			From:     cfg.alert.smtp.from,
+			To:       splitList(cfg.alert.smtp.to),
+			Username: cfg.alert.smtp.username,
+			Password: cfg.alert.smtp.password,
+		}))
+	}
+
+	return sinks, nil
+}
+
+// routeLimits reads the per-route limit table from path, or returns the
+// built-in one when path is empty.
+func routeLimits(path string) (ratelimit.Table, error) {
+	raw := mid.DefaultRouteLimits
+
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		raw = nil
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return ratelimit.ParseTable(raw)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+)
+
+var errShuttingDown = errors.New("lifecycle is shutting down")
+
+// lifecycle owns the background goroutines of the process so that shutdown
+// can stop them and wait for them to finish.
+type lifecycle struct {
+	log    *logger.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	stopping bool
+	running  map[string]int
+	errs     []error
+}
+
+func newLifecycle(log *logger.Logger) *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &lifecycle{
+		log:     log,
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]int),
+	}
+}
+
+// Go starts fn as a named component. fn must return once ctx is canceled.
+func (lc *lifecycle) Go(name string, fn func(ctx context.Context) error) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if lc.stopping {
+		lc.log.Warn(lc.ctx, "rejected background component", "component", name, "error", errShuttingDown)
+		return errShuttingDown
+	}
+
+	lc.running[name]++
+
+	lc.wg.Go(func() {
+		err := fn(lc.ctx)
+
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+
+		if lc.running[name]--; lc.running[name] == 0 {
+			delete(lc.running, name)
+		}
+
+		if err != nil && !errors.Is(err, context.Canceled) {
+			lc.errs = append(lc.errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+
+	return nil
+}
+
+// Shutdown stops accepting new components, cancels the running ones and
+// waits for them until ctx is done. The returned error names every component
+// that failed or did not stop in time.
+func (lc *lifecycle) Shutdown(ctx context.Context) error {
+	lc.mu.Lock()
+	lc.stopping = true
+	lc.mu.Unlock()
+
+	lc.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		lc.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	errs := slices.Clone(lc.errs)
+
+	if len(lc.running) > 0 {
+		names := make([]string, 0, len(lc.running))
+		for name := range lc.running {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		errs = append(errs, fmt.Errorf("components failed to stop: %s", strings.Join(names, ", ")))
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,17 +2,31 @@ package main
 
 import (
 	"context"
-	"flag"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/agkmw/reddit-clone/internal/api/sdk/debug"
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mux"
+	"github.com/agkmw/reddit-clone/internal/app/domain/flagapp"
+	"github.com/agkmw/reddit-clone/internal/database/flagdb"
+	"github.com/agkmw/reddit-clone/internal/database/idempotencydb"
+	"github.com/agkmw/reddit-clone/internal/database/ratelimitdb"
+	"github.com/agkmw/reddit-clone/internal/platform/alert"
+	"github.com/agkmw/reddit-clone/internal/platform/conf"
 	"github.com/agkmw/reddit-clone/internal/platform/db"
+	"github.com/agkmw/reddit-clone/internal/platform/feature"
 	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/metrics"
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
+	"github.com/agkmw/reddit-clone/internal/platform/trace"
 	"github.com/agkmw/reddit-clone/internal/platform/web"
 )
 
@@ -20,28 +34,6 @@ const version = "1.0.0"
 
 var build = "dev"
 
-type config struct {
-	port        int
-	environment string
-	limiter     struct {
-		enabled bool
-		rps     float64
-		burst   int
-	}
-	db struct {
-		dsn string
-
-		maxConns     int
-		minConns     int
-		minIdleConns int
-
-		maxConnIdleTime time.Duration
-		maxConnLifeTime time.Duration
-
-		healthCheckPeriod time.Duration
-	}
-}
-
 func main() {
 	ctx := context.Background()
 	if err := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr); err != nil {
@@ -62,110 +54,178 @@ func run(
 	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	// SIGHUP terminates the process by default. Ignore it until the
+	// reloader and the log file reopener start watching for it.
+	signal.Ignore(syscall.SIGHUP)
+
 	// -------------------------------------------------------------------------
 
-	var cfg config
-
-	fs := flag.NewFlagSet("reddit-clone", flag.PanicOnError)
-
-	fs.IntVar(
-		&cfg.port,
-		"port",
-		4000,
-		"Application server port",
-	)
-	fs.StringVar(
-		&cfg.environment,
-		"environment",
-		"development",
-		"Environment (development|staging|production)",
-	)
-
-	fs.Float64Var(
-		&cfg.limiter.rps,
-		"limiter-rps",
-		2,
-		"Rate limiter maximum requests per second",
-	)
-	fs.IntVar(
-		&cfg.limiter.burst,
-		"limiter-burst",
-		4,
-		"Rate limiter maximum burst",
-	)
-	fs.BoolVar(
-		&cfg.limiter.enabled,
-		"limiter-enabled",
-		true,
-		"Enable rate limiter",
-	)
-
-	fs.StringVar(
-		&cfg.db.dsn,
-		"db-dsn",
-		"",
-		"PostgreSQL DSN",
-	)
-	fs.IntVar(
-		&cfg.db.maxConns,
-		"db-max-conns",
-		25,
-		"PostgreSQL max open connections",
-	)
-	fs.IntVar(
-		&cfg.db.minConns,
-		"db-min-conns",
-		5,
-		"PostgreSQL min open connections",
-	)
-	fs.IntVar(
-		&cfg.db.minIdleConns,
-		"db-min-idle-conns",
-		25,
-		"PostgreSQL min idle connections",
-	)
-
-	fs.DurationVar(
-		&cfg.db.maxConnIdleTime,
-		"db-max-idle-time",
-		15*time.Minute,
-		"PostgeSQL max connection idle time",
-	)
-	fs.DurationVar(
-		&cfg.db.maxConnLifeTime,
-		"db-max-life-time",
-		2*time.Hour,
-		"PostgeSQL max connection life time",
-	)
-	fs.DurationVar(
-		&cfg.db.healthCheckPeriod,
-		"db-heathz-period",
-		time.Minute,
-		"PostgeSQL health check period",
-	)
-
-	fs.Parse(args)
+	cfg, fs, sources, err := loadConfig(args, getenv, stderr)
+	if err != nil {
+		if errors.Is(err, conf.ErrHelp) {
+			return nil
+		}
+
+		return fmt.Errorf("parsing config: %w", err)
+	}
+
+	if cfg.dump {
+		return writeConfig(stdout, fs, sources)
+	}
+
+	if err := conf.Validate(fs, confConfig(getenv)); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	initial, err := newSettings(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	live, err := conf.NewLive(initial, validateSettings)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Everything else built from the config is checked here too, before any
+	// component starts and would need stopping on the way out.
+
+	clientIP, err := web.NewIPResolver(splitList(cfg.proxy.trusted))
+	if err != nil {
+		return fmt.Errorf("invalid config: trusted-proxies: %w", err)
+	}
+
+	var cors *web.CORS
+	if cfg.cors.origins != "" {
+		cors, err = web.NewCORS(web.CORSConfig{
+			Origins:     splitList(cfg.cors.origins),
+			Credentials: cfg.cors.credentials,
+			MaxAge:      cfg.cors.maxAge,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid config: cors-origins: %w", err)
+		}
+	}
+
+	switch cfg.limiter.store {
+	case "memory", "postgres":
+	default:
+		return fmt.Errorf("invalid config: limiter-store: unknown store %q", cfg.limiter.store)
+	}
+
+	routes, err := routeLimits(cfg.limiter.routes)
+	if err != nil {
+		return fmt.Errorf("invalid config: limiter-routes: %w", err)
+	}
 
 	// -------------------------------------------------------------------------
 
 	var log *logger.Logger
+	var logFile *logger.FileWriter
 
 	traceIDFn := func(ctx context.Context) string {
 		return web.GetTraceID(ctx)
 	}
 
+	sinks, err := alertSinks(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	alerts := alert.NewDispatcher(alert.Config{
+		Sinks:         sinks,
+		FlushInterval: cfg.alert.flush,
+		DedupWindow:   cfg.alert.dedup,
+		PerMinute:     cfg.alert.perMinute,
+
+		// Logged below error level so a failing sink cannot raise alerts
+		// about itself.
+		ErrorFn: func(ctx context.Context, sink string, err error) {
+			log.Warn(ctx, "failed to deliver alerts", "sink", sink, "error", err)
+		},
+	})
+
 	events := logger.Events{
 		Error: func(ctx context.Context, r logger.Record) {
-			log.Info(ctx, "******* SEND ALERT! *******")
+			alerts.Notify(alert.Alert{
+				Service:    "reddit-clone",
+				Time:       r.Time,
+				Level:      r.Level.String(),
+				Message:    r.Message,
+				Attributes: r.Attributes,
+			})
 		},
 	}
 
-	log = logger.NewWithEvents(
-		stdout,
-		logger.LevelInfo,
-		"reddit-clone",
-		traceIDFn,
-		events)
+	sampling, err := logger.ParseSampling(cfg.log.sample)
+	if err != nil {
+		return fmt.Errorf("invalid config: log-sample: %w", err)
+	}
+
+	redaction := logger.DefaultRedaction.With(splitList(cfg.log.redact)...)
+
+	format := logger.FormatJSON
+	if cfg.environment == "development" {
+		format = logger.FormatConsole
+	}
+
+	if cfg.log.format != "" {
+		if format, err = logger.ParseFormat(cfg.log.format); err != nil {
+			return fmt.Errorf("invalid config: log-format: %w", err)
+		}
+	}
+
+	logOut := stdout
+
+	if cfg.log.file.path != "" {
+		fw, err := logger.NewFileWriter(logger.FileConfig{
+			Path:       cfg.log.file.path,
+			MaxSize:    int64(cfg.log.file.maxSize) << 20,
+			MaxAge:     cfg.log.file.maxAge,
+			MaxBackups: cfg.log.file.maxBackups,
+			Compress:   cfg.log.file.compress,
+		})
+		if err != nil {
+			return fmt.Errorf("opening log file: %w", err)
+		}
+		defer fw.Close()
+
+		logOut = fw
+		logFile = fw
+	}
+
+	log = logger.NewWithConfig(logOut, logger.Config{
+		MinLevel:    initial.logLevel,
+		ServiceName: "reddit-clone",
+		TraceIDFn:   traceIDFn,
+		Events:      events,
+		Redaction:   &redaction,
+		Sampling:    sampling,
+		Format:      format,
+		Color:       isTerminal(logOut) && getenv("NO_COLOR") == "",
+	})
+
+	// -------------------------------------------------------------------------
+
+	var exporter trace.Exporter
+	if cfg.tracing.endpoint != "" {
+		exporter = trace.NewOTLPExporter(cfg.tracing.endpoint, "reddit-clone")
+	}
+
+	tracing := trace.NewProvider(trace.Config{
+		Exporter: exporter,
+		ErrorFn: func(err error) {
+			log.Error(ctx, "failed to export spans", "error", err)
+		},
+	})
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := tracing.Shutdown(ctx); err != nil {
+			log.Error(ctx, "failed to flush spans", "error", err)
+		}
+	}()
 
 	// -------------------------------------------------------------------------
 
@@ -180,6 +240,8 @@ func run(
 		MaxConnLifeTime: cfg.db.maxConnLifeTime,
 
 		HealthCheckPeriod: cfg.db.healthCheckPeriod,
+
+		Tracer: trace.NewQueryTracer(tracing),
 	}
 
 	pool, err := db.Open(ctx, dbCfg)
@@ -192,10 +254,145 @@ func run(
 
 	// -------------------------------------------------------------------------
 
-	// TODO:
-	var mux http.Handler
+	reg := metrics.NewRegistry()
+	metrics.RegisterRuntime(reg)
+	db.RegisterMetrics(reg, pool)
+
+	// Nothing below returns before serve, which stops the lifecycle on the
+	// way out.
+	lc := newLifecycle(log)
+
+	lc.Go("alert dispatcher", alerts.Run)
+
+	if logFile != nil {
+		lc.Go("log file reopener", reopenOnHUP(logFile, log))
+	}
+
+	live.Subscribe(func(s settings) {
+		log.SetLevel(s.logLevel)
+	})
+
+	reload := newReloader(args, getenv, cfg, fs, live, log)
+
+	debugServer := http.Server{
+		Addr: cfg.debug.host,
+		Handler: debug.Mux(debug.Config{
+			Build:    build,
+			Version:  version,
+			Metrics:  reg,
+			Pool:     pool,
+			Settings: reload.settings,
+		}),
+		ErrorLog: logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	lc.Go("debug server", func(lcCtx context.Context) error {
+		serverErrors := make(chan error, 1)
+
+		go func() {
+			log.Info(ctx, "starting debug server", "addr", debugServer.Addr)
+			serverErrors <- debugServer.ListenAndServe()
+		}()
+
+		select {
+		case err := <-serverErrors:
+			log.Error(ctx, "debug server closed", "addr", debugServer.Addr, "error", err)
+			return err
+
+		case <-lcCtx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			return debugServer.Shutdown(ctx)
+		}
+	})
+
+	// -------------------------------------------------------------------------
+
+	flags := feature.New(feature.Config{
+		Load:     flagapp.Loader(flagdb.New(pool)),
+		Interval: cfg.flags.refresh,
+		ErrorFn: func(ctx context.Context, err error) {
+			log.Error(ctx, "failed to refresh feature flags", "error", err)
+		},
+	})
+
+	if err := flags.Refresh(ctx); err != nil {
+		log.Error(ctx, "failed to load feature flags, all flags are off", "error", err)
+	}
+
+	lc.Go("feature flag refresher", flags.Run)
+
+	// -------------------------------------------------------------------------
+
+	// The store was checked with the rest of the config.
+	var limiter ratelimit.Limiter
+	switch cfg.limiter.store {
+	case "memory":
+		mem := ratelimit.NewMemory()
+		lc.Go("rate limiter janitor", mem.Run)
+		limiter = mem
+
+	case "postgres":
+		store := ratelimitdb.New(pool)
+		lc.Go("rate limiter janitor", store.Run)
+		limiter = store
+	}
+
+	// The limit is read per request, so reloads apply without rebuilding
+	// the middleware.
+	limit := func(ctx context.Context) (ratelimit.Limit, bool) {
+		s := live.Load()
+		return ratelimit.PerSecond(s.limiter.rps, s.limiter.burst), s.limiter.enabled
+	}
+
+	idempotent := idempotencydb.New(pool)
+	lc.Go("idempotency key sweeper", idempotent.Run)
+
+	var draining atomic.Bool
+
+	webAPI := mux.WebAPI(mux.Config{
+		Environment: cfg.environment,
+		Version:     version,
+		Build:       build,
+		Limiter: mid.LimiterConfig{
+			Limiter: limiter,
+			Limit:   limit,
+			Routes:  routes,
+			Enabled: func() bool { return live.Load().limiter.enabled },
+		},
+		Problem: web.ProblemConfig{
+			Always:  cfg.problem.always,
+			TypeURI: cfg.problem.typeURI,
+		},
+		Pool:     pool,
+		Log:      log,
+		Metrics:  reg,
+		Tracing:  tracing,
+		Draining: draining.Load,
+		Flags:    flags,
+		ClientIP: clientIP,
+		CORS:     cors,
+		Compression: web.CompressionConfig{
+			Disabled: !cfg.compression.enabled,
+			MinSize:  cfg.compression.minSize,
+		},
+		Idempotency: web.IdempotencyConfig{
+			Store: idempotent,
+			TTL:   cfg.idempotency.ttl,
+		},
+		Security: web.SecurityConfig{
+			HSTSMaxAge: cfg.security.hstsMaxAge,
+		},
+		MaxBodyBytes: cfg.request.maxBodyBytes,
+
+		AdminToken: cfg.admin.token,
+	})
+
+	// Every subscriber is in place, so reloads can start.
+	lc.Go("settings reloader", reload.run)
 
-	if err := serve(ctx, cfg, mux, log); err != nil {
+	if err := serve(ctx, cfg, webAPI, &draining, lc, log); err != nil {
 		return fmt.Errorf("server failed %w", err)
 	}
 
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/agkmw/reddit-clone/internal/platform/conf"
+)
+
+const redacted = "xxxxxx"
+
+// secretFlags are hidden entirely. Webhook URLs are among them because
+// services such as Slack put the token in the path.
+var secretFlags = []string{"dsn", "password", "secret", "token", "key", "webhook"}
+
+var dsnPasswordRX = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// redactedSettings returns every flag with its effective value, hiding
+// secrets. Only the password part of a DSN is masked, and other URLs lose
+// their credentials and query values.
+func redactedSettings(fs *flag.FlagSet) map[string]string {
+	settings := make(map[string]string)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		settings[f.Name] = redact(f.Name, f.Value.String())
+	})
+
+	return settings
+}
+
+func redact(name, value string) string {
+	if value == "" {
+		return value
+	}
+
+	lower := strings.ToLower(name)
+
+	if strings.Contains(lower, "dsn") {
+		return redactDSN(value)
+	}
+
+	for _, s := range secretFlags {
+		if strings.Contains(lower, s) {
+			return redacted
+		}
+	}
+
+	return redactURL(value)
+}
+
+// redactURL masks the userinfo and query values of value when it is a URL,
+// since either may carry a token.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return value
+	}
+
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for name := range q {
+			q.Set(name, redacted)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String()
+}
+
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+
+		return u.String()
+	}
+
+	return dsnPasswordRX.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// writeConfig prints the effective configuration, redacted, with the source
+// of every value.
+func writeConfig(w io.Writer, fs *flag.FlagSet, sources conf.Sources) error {
+	type setting struct {
+		Value  string      `json:"value"`
+		Source conf.Source `json:"source"`
+	}
+
+	settings := make(map[string]setting)
+	for name, value := range redactedSettings(fs) {
+		settings[name] = setting{Value: value, Source: sources[name]}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(settings)
+}
@@ -5,9 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"github.com/agkmw/reddit-clone/internal/platform/logger"
@@ -17,48 +15,69 @@ func serve(
 	ctx context.Context,
 	cfg config,
 	mux http.Handler,
+	draining *atomic.Bool,
+	lc *lifecycle,
 	log *logger.Logger,
 ) error {
 	server := http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.port),
-		Handler:      mux,
-		IdleTimeout:  2 * time.Minute,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
+		Addr:           fmt.Sprintf(":%d", cfg.port),
+		Handler:        mux,
+		IdleTimeout:    2 * time.Minute,
+		ReadTimeout:    5 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: cfg.request.maxHeaderBytes,
+		ErrorLog:       logger.NewStdLogger(log, logger.LevelError),
 	}
 
-	shutdownErr := make(chan error)
+	serverErrors := make(chan error, 1)
 
 	go func() {
-		shutdown := make(chan os.Signal, 1)
-		signal.Notify(shutdown, syscall.SIGTERM, syscall.SIGINT)
-		sig := <-shutdown
-
-		log.Info(ctx, "gracefully shutting down the server", "signal", sig.String())
+		log.Info(ctx, "starting server", "addr", server.Addr, "env", cfg.environment)
+		serverErrors <- server.ListenAndServe()
+	}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	select {
+	case err := <-serverErrors:
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdown.timeout)
 		defer cancel()
 
-		err := server.Shutdown(ctx)
-		if err != nil {
-			shutdownErr <- err
+		if lcErr := lc.Shutdown(shutdownCtx); lcErr != nil {
+			log.Error(ctx, "failed to stop background tasks", "error", lcErr)
 		}
 
-		log.Info(ctx, "completing background tasks", "addr", server.Addr)
+		return fmt.Errorf("server error: %w", err)
 
-		// app.wg.Wait()
-		shutdownErr <- nil
-	}()
+	case <-ctx.Done():
+		draining.Store(true)
 
-	log.Info(ctx, "starting server", "addr", server.Addr, "env", cfg.environment)
+		log.Info(ctx, "draining before shutdown", "cause", context.Cause(ctx), "drain", cfg.shutdown.drain.String())
+		time.Sleep(cfg.shutdown.drain)
 
-	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-		return err
-	}
+		log.Info(ctx, "gracefully shutting down the server", "timeout", cfg.shutdown.timeout.String())
 
-	if err := <-shutdownErr; err != nil {
-		return err
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdown.timeout)
+		defer cancel()
+
+		var errs []error
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			server.Close()
+			errs = append(errs, fmt.Errorf("could not stop server gracefully: %w", err))
+		}
+
+		if err := <-serverErrors; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+
+		log.Info(ctx, "completing background tasks", "addr", server.Addr)
+
+		if err := lc.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("could not stop background tasks: %w", err))
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
 	}
 
 	log.Info(ctx, "server gracefully shut down")
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/conf"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+)
+
+// settings is the part of the config that can change without a restart.
+type settings struct {
+	logLevel logger.Level
+	limiter  struct {
+		enabled bool
+		rps     float64
+		burst   int
+	}
+}
+
+// reloadable lists the flags settings is built from. Changes to any other
+// flag are reported on reload but only take effect after a restart.
+var reloadable = []string{
+	"log-level",
+	"limiter-enabled",
+	"limiter-rps",
+	"limiter-burst",
+}
+
+func newSettings(cfg config) (settings, error) {
+	var s settings
+
+	level, err := logger.ParseLevel(cfg.log.level)
+	if err != nil {
+		return settings{}, fmt.Errorf("log-level: %w", err)
+	}
+
+	s.logLevel = level
+	s.limiter.enabled = cfg.limiter.enabled
+	s.limiter.rps = cfg.limiter.rps
+	s.limiter.burst = cfg.limiter.burst
+
+	return s, nil
+}
+
+func validateSettings(s settings) error {
+	var errs []error
+
+	if s.limiter.rps <= 0 {
+		errs = append(errs, fmt.Errorf("limiter-rps must be positive, got %v", s.limiter.rps))
+	}
+	if s.limiter.burst < 1 {
+		errs = append(errs, fmt.Errorf("limiter-burst must be at least 1, got %d", s.limiter.burst))
+	}
+
+	return errors.Join(errs...)
+}
+
+// =============================================================================
+
+// reloader reloads the runtime settings on SIGHUP and whenever the config
+// file changes. A reload that fails to parse or validate keeps the current
+// settings.
+type reloader struct {
+	args   []string
+	getenv func(string) string
+	log    *logger.Logger
+	live   *conf.Live[settings]
+
+	file     string
+	interval time.Duration
+	hup      chan os.Signal
+
+	startup map[string]string
+	current atomic.Pointer[map[string]string]
+}
+
+func newReloader(
+	args []string,
+	getenv func(string) string,
+	cfg config,
+	fs *flag.FlagSet,
+	live *conf.Live[settings],
+	log *logger.Logger,
+) *reloader {
+	r := reloader{
+		args:     args,
+		getenv:   getenv,
+		log:      log,
+		live:     live,
+		file:     cfg.file,
+		interval: cfg.watch,
+		hup:      make(chan os.Signal, 1),
+		startup:  redactedSettings(fs),
+	}
+	r.current.Store(&r.startup)
+
+	// Signals that arrive before run starts are queued rather than lost.
+	signal.Notify(r.hup, syscall.SIGHUP)
+
+	return &r
+}
+
+// settings returns the redacted flags in effect: the startup values, with
+// the reloadable ones as of the last successful reload.
+func (r *reloader) settings() map[string]string {
+	return maps.Clone(*r.current.Load())
+}
+
+func (r *reloader) run(ctx context.Context) error {
+	defer signal.Stop(r.hup)
+
+	var tick <-chan time.Time
+	var modTime time.Time
+
+	if r.file != "" && r.interval > 0 {
+		if fi, err := os.Stat(r.file); err == nil {
+			modTime = fi.ModTime()
+		}
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-r.hup:
+			r.reload(ctx, "SIGHUP")
+
+		case <-tick:
+			fi, err := os.Stat(r.file)
+			if err != nil || fi.ModTime().Equal(modTime) {
+				continue
+			}
+
+			modTime = fi.ModTime()
+			r.reload(ctx, "config file changed")
+		}
+	}
+}
+
+func (r *reloader) reload(ctx context.Context, reason string) {
+	cfg, fs, _, err := loadConfig(r.args, r.getenv, io.Discard)
+	if err == nil {
+		err = conf.Validate(fs, confConfig(r.getenv))
+	}
+
+	var next settings
+	if err == nil {
+		next, err = newSettings(cfg)
+	}
+
+	if err == nil {
+		err = r.live.Update(next)
+	}
+
+	if err != nil {
+		r.log.Error(ctx, "reload settings: keeping current settings", "reason", reason, "error", err)
+		return
+	}
+
+	current := maps.Clone(r.startup)
+
+	for name, value := range redactedSettings(fs) {
+		switch {
+		case slices.Contains(reloadable, name):
+			current[name] = value
+		case r.startup[name] != value:
+			r.log.Warn(ctx, "reload settings: restart required", "setting", name)
+		}
+	}
+
+	r.current.Store(&current)
+
+	r.log.Info(ctx, "reload settings: applied", "reason", reason, "log_level", next.logLevel.String(),
+		"limiter_enabled", next.limiter.enabled, "limiter_rps", next.limiter.rps, "limiter_burst", next.limiter.burst)
+}
+
+// reopenOnHUP returns a task that reopens the log file on SIGHUP so that
+// external tools such as logrotate can move it aside. The signal is watched
+// from the call on.
+func reopenOnHUP(fw *logger.FileWriter, log *logger.Logger) func(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	return func(ctx context.Context) error {
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-hup:
+				if err := fw.Reopen(); err != nil {
+					log.Warn(ctx, "failed to reopen log file", "error", err)
+					continue
+				}
+
+				log.Info(ctx, "reopened log file")
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package flagapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/agkmw/reddit-clone/internal/app/domain/flagapp"
+	"github.com/agkmw/reddit-clone/internal/database/flagdb"
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/feature"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/validator"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+var nameRX = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+type api struct {
+	db    *flagdb.Store
+	flags *feature.Flags
+	log   *logger.Logger
+}
+
+func newAPI(cfg Config) *api {
+	return &api{
+		db:    cfg.Store,
+		flags: cfg.Flags,
+		log:   cfg.Log,
+	}
+}
+
+func (a *api) CreateFlagHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var input struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Enabled     bool   `json:"enabled"`
+		Rollout     *int   `json:"rollout"`
+	}
+
+	if err := web.Decode(w, r, &input); err != nil {
+		return err
+	}
+
+	flag := flagdb.Flag{
+		Name:        input.Name,
+		Description: input.Description,
+		Enabled:     input.Enabled,
+		Rollout:     100,
+	}
+
+	if input.Rollout != nil {
+		flag.Rollout = *input.Rollout
+	}
+
+	v := validator.New()
+
+	v.CheckRule(flag.Name != "", "name", validator.RequiredRule())
+	v.CheckRule(validator.MaxChars(flag.Name, 64), "name", validator.MaxLengthRule(64))
+	v.CheckRule(validator.Matches(flag.Name, nameRX), "name", validator.MatchesRule())
+	v.CheckRule(validator.MaxChars(flag.Description, 500), "description", validator.MaxLengthRule(500))
+	v.CheckRule(flag.Rollout >= 0 && flag.Rollout <= 100, "rollout", validator.BetweenRule(0, 100))
+
+	if !v.Valid() {
+		return errs.New(errs.FailedValidation, errors.New("invalid feature flag input"), v.Errors)
+	}
+
+	if err := a.db.Create(ctx, &flag); err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrFlagAlreadyExists):
+			return errs.NewClientError(errs.AlreadyExists, err, i18n.Message{Key: "flag.already_exists"})
+		default:
+			return errs.Wrap(err, "create feature flag", errs.ErrorInfo{"flag": flag.Name})
+		}
+	}
+
+	a.refresh(ctx)
+
+	return web.Encode(ctx, w, http.StatusCreated, web.Envelope{
+		"status": "success",
+		"data":   flagapp.ToAppFlag(flag),
+	})
+}
+
+func (a *api) GetFlagHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.ReadParam(r, "name")
+
+	flag, err := a.db.GetFlag(ctx, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrRecordNotFound):
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "flag.not_found"})
+		default:
+			return errs.Wrap(err, "get feature flag", errs.ErrorInfo{"flag": name})
+		}
+	}
+
+	env := web.Envelope{
+		"status": "success",
+		"data": map[string]any{
+			"flag": flagapp.ToAppFlag(*flag),
+		},
+	}
+
+	return web.Encode(ctx, w, http.StatusOK, env)
+}
+
+func (a *api) ListFlagsHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	flags, err := a.db.GetFlags(ctx)
+	if err != nil {
+		return errs.Wrap(err, "list feature flags", nil)
+	}
+
+	env := web.Envelope{
+		"status": "success",
+		"data": map[string]any{
+			"flags": flagapp.ToAppFlags(flags),
+		},
+	}
+
+	return web.Encode(ctx, w, http.StatusOK, env)
+}
+
+func (a *api) UpdateFlagHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.ReadParam(r, "name")
+
+	flag, err := a.db.GetFlag(ctx, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrRecordNotFound):
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "flag.not_found"})
+		default:
+			return errs.Wrap(err, "get feature flag", errs.ErrorInfo{"flag": name})
+		}
+	}
+
+	var input struct {
+		Description *string `json:"description"`
+		Enabled     *bool   `json:"enabled"`
+		Rollout     *int    `json:"rollout"`
+	}
+
+	if err := web.Decode(w, r, &input); err != nil {
+		return err
+	}
+
+	v := validator.New()
+
+	if input.Description != nil {
+		flag.Description = *input.Description
+		v.CheckRule(validator.MaxChars(flag.Description, 500), "description", validator.MaxLengthRule(500))
+	}
+
+	if input.Enabled != nil {
+		flag.Enabled = *input.Enabled
+	}
+
+	if input.Rollout != nil {
+		flag.Rollout = *input.Rollout
+		v.CheckRule(flag.Rollout >= 0 && flag.Rollout <= 100, "rollout", validator.BetweenRule(0, 100))
+	}
+
+	if !v.Valid() {
+		return errs.New(errs.FailedValidation, errors.New("invalid feature flag update input"), v.Errors)
+	}
+
+	if err := a.db.Update(ctx, flag); err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrEditConflict):
+			return errs.NewClientError(errs.Aborted, err, i18n.Message{Key: "flag.edit_conflict"})
+		default:
+			return errs.Wrap(err, "update feature flag", errs.ErrorInfo{"flag": name})
+		}
+	}
+
+	a.refresh(ctx)
+
+	env := web.Envelope{
+		"status": "success",
+		"data": map[string]any{
+			"flag": flagapp.ToAppFlag(*flag),
+		},
+	}
+
+	return web.Encode(ctx, w, http.StatusOK, env)
+}
+
+func (a *api) DeleteFlagHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.ReadParam(r, "name")
+
+	if err := a.db.Delete(ctx, name); err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrRecordNotFound):
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "flag.not_found"})
+		default:
+			return errs.Wrap(err, "delete feature flag", errs.ErrorInfo{"flag": name})
+		}
+	}
+
+	a.refresh(ctx)
+
+	return web.Encode(ctx, w, http.StatusOK, web.Envelope{
+		"status": "success",
+		"data":   "feature flag deleted successfully",
+	})
+}
+
+func (a *api) SetOverrideHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.ReadParam(r, "name")
+
+	var input struct {
+		Enabled *bool `json:"enabled"`
+	}
+
+	if err := web.Decode(w, r, &input); err != nil {
+		return err
+	}
+
+	o := flagdb.Override{
+		Kind:    web.ReadParam(r, "kind"),
+		Subject: web.ReadParam(r, "subject"),
+	}
+
+	v := validator.New()
+
+	v.CheckRule(validator.IsPermitted([]string{flagdb.OverrideUser, flagdb.OverrideRole}, o.Kind), "kind", validator.PermittedRule())
+	v.CheckRule(input.Enabled != nil, "enabled", validator.RequiredRule())
+
+	if !v.Valid() {
+		return errs.New(errs.FailedValidation, errors.New("invalid feature flag override input"), v.Errors)
+	}
+
+	o.Enabled = *input.Enabled
+
+	if err := a.db.SetOverride(ctx, name, o); err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrRecordNotFound):
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "flag.not_found"})
+		default:
+			return errs.Wrap(err, "set feature flag override", errs.ErrorInfo{"flag": name, "kind": o.Kind})
+		}
+	}
+
+	a.refresh(ctx)
+
+	return web.Encode(ctx, w, http.StatusOK, web.Envelope{
+		"status": "success",
+		"data": flagapp.Override{
+			Kind:    o.Kind,
+			Subject: o.Subject,
+			Enabled: o.Enabled,
+		},
+	})
+}
+
+func (a *api) DeleteOverrideHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.ReadParam(r, "name")
+	kind := web.ReadParam(r, "kind")
+	subject := web.ReadParam(r, "subject")
+
+	if err := a.db.DeleteOverride(ctx, name, kind, subject); err != nil {
+		switch {
+		case errors.Is(err, flagdb.ErrRecordNotFound):
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "flag.override_not_found"})
+		default:
+			return errs.Wrap(err, "delete feature flag override", errs.ErrorInfo{"flag": name, "kind": kind})
+		}
+	}
+
+	a.refresh(ctx)
+
+	return web.Encode(ctx, w, http.StatusOK, web.Envelope{
+		"status": "success",
+		"data":   "override deleted successfully",
+	})
+}
+
+// refresh makes a change visible on this replica right away. Other replicas
+// pick it up on their next periodic refresh.
+func (a *api) refresh(ctx context.Context) {
+	if err := a.flags.Refresh(ctx); err != nil {
+		a.log.Warn(ctx, "refresh feature flags", "error", err)
+	}
+}
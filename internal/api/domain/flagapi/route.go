@@ -0,0 +1,33 @@
+package flagapi
+
+import (
+	"net/http"
+
+	"github.com/agkmw/reddit-clone/internal/database/flagdb"
+	"github.com/agkmw/reddit-clone/internal/platform/feature"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+type Config struct {
+	Store *flagdb.Store
+	Flags *feature.Flags
+	Log   *logger.Logger
+
+	// Auth guards every admin route.
+	Auth web.Middleware
+}
+
+func Routes(app *web.App, cfg Config) {
+	api := newAPI(cfg)
+
+	const group = "/v1/admin"
+
+	app.HandlerFuncWithMid(http.MethodGet, group, "/flags", api.ListFlagsHandler, cfg.Auth)
+	app.HandlerFuncWithMid(http.MethodPost, group, "/flags", api.CreateFlagHandler, cfg.Auth)
+	app.HandlerFuncWithMid(http.MethodGet, group, "/flags/{name}", api.GetFlagHandler, cfg.Auth)
+	app.HandlerFuncWithMid(http.MethodPatch, group, "/flags/{name}", api.UpdateFlagHandler, cfg.Auth)
+	app.HandlerFuncWithMid(http.MethodDelete, group, "/flags/{name}", api.DeleteFlagHandler, cfg.Auth)
+	app.HandlerFuncWithMid(http.MethodPut, group, "/flags/{name}/overrides/{kind}/{subject}", api.SetOverrideHandler, cfg.Auth)
+	app.HandlerFuncWithMid(http.MethodDelete, group, "/flags/{name}/overrides/{kind}/{subject}", api.DeleteOverrideHandler, cfg.Auth)
+}
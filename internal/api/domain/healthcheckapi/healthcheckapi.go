@@ -3,16 +3,40 @@ package healthcheckapi
 import (
 	"context"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/agkmw/reddit-clone/internal/platform/web"
 )
 
+type check struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+type checkResult struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+}
+
 type api struct {
-	cfg Config
+	cfg    Config
+	checks []check
 }
 
 func newAPI(cfg Config) *api {
-	return &api{cfg: cfg}
+	if cfg.CheckTimeout <= 0 {
+		cfg.CheckTimeout = time.Second
+	}
+
+	a := api{cfg: cfg}
+
+	if cfg.Pool != nil {
+		a.checks = append(a.checks, check{name: "database", fn: cfg.Pool.Ping})
+	}
+
+	return &a
 }
 
 func (api *api) healthcheckHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -22,5 +46,87 @@ func (api *api) healthcheckHandler(ctx context.Context, w http.ResponseWriter, r
 		"build":       api.cfg.Build,
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, data)
+	return web.Encode(ctx, w, http.StatusOK, data)
+}
+
+// livenessHandler only reports that the process is able to serve requests;
+// it must not depend on anything outside the process.
+func (api *api) livenessHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	data := web.Envelope{
+		"status":  "up",
+		"host":    host,
+		"version": api.cfg.Version,
+		"build":   api.cfg.Build,
+	}
+
+	return web.Encode(ctx, w, http.StatusOK, data)
+}
+
+func (api *api) readinessHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.cfg.Draining != nil && api.cfg.Draining() {
+		data := web.Envelope{
+			"status": "draining",
+		}
+
+		return web.Encode(ctx, w, http.StatusServiceUnavailable, data)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		ready   = true
+		results = make(map[string]checkResult, len(api.checks))
+	)
+
+	for _, c := range api.checks {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, api.cfg.CheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.fn(ctx)
+			took := time.Since(start)
+
+			result := checkResult{
+				Status:  "up",
+				Latency: took.String(),
+			}
+
+			if err != nil {
+				result.Status = "down"
+
+				if api.cfg.Log != nil {
+					api.cfg.Log.Warn(ctx, "readiness check failed", "check", c.name, "latency", took.String(), "error", err)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			results[c.name] = result
+			if err != nil {
+				ready = false
+			}
+		})
+	}
+
+	wg.Wait()
+
+	status := http.StatusOK
+	data := web.Envelope{
+		"status": "ready",
+		"checks": results,
+	}
+
+	if !ready {
+		status = http.StatusServiceUnavailable
+		data["status"] = "not_ready"
+	}
+
+	return web.Encode(ctx, w, status, data)
 }
@@ -2,18 +2,32 @@ package healthcheckapi
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
 	"github.com/agkmw/reddit-clone/internal/platform/web"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Config struct {
 	Environment string
 	Build       string
 	Version     string
+	Log         *logger.Logger
+	Pool        *pgxpool.Pool
+
+	// Draining reports whether the service is shutting down and should be
+	// taken out of rotation.
+	Draining func() bool
+
+	// CheckTimeout bounds each dependency check done by the readiness probe.
+	CheckTimeout time.Duration
 }
 
 func Routes(app *web.App, cfg Config) {
 	api := newAPI(cfg)
 
 	app.HandlerFunc(http.MethodGet, "/v1", "/healthcheck", api.healthcheckHandler)
+	app.HandlerFunc(http.MethodGet, "/v1", "/liveness", api.livenessHandler)
+	app.HandlerFunc(http.MethodGet, "/v1", "/readiness", api.readinessHandler)
 }
@@ -7,12 +7,38 @@ import (
 	"github.com/agkmw/reddit-clone/internal/platform/web"
 )
 
-func Routes(app *web.App, db *userdb.Store) {
-	api := newAPI(db)
-
-	app.HandlerFunc(http.MethodGet, "/v1", "/users", api.ListUsersHandler)
-	app.HandlerFunc(http.MethodPost, "/v1", "/users", api.RegisterUserHandler)
-	app.HandlerFunc(http.MethodGet, "/v1", "/users/{username}", api.GetUserHandler)
-	app.HandlerFunc(http.MethodPatch, "/v1", "/users/{username}", api.UpdateUserHandler)
-	app.HandlerFunc(http.MethodDelete, "/v1", "/users/{username}", api.DeleteUserHandler)
+type Config struct {
+	Store *userdb.Store
+
+	// Limit returns the rate limit middleware for a route, or nil when the
+	// route has no limit of its own.
+	Limit func(method, pattern string) web.Middleware
+
+	// Idempotent makes retries of creating requests safe.
+	Idempotent web.Middleware
+}
+
+// maxBodyBytes is far more than any user body needs.
+const maxBodyBytes = 4 * 1024
+
+func Routes(app *web.App, cfg Config) {
+	api := newAPI(cfg.Store)
+
+	const group = "/v1"
+
+	limit := cfg.Limit
+	if limit == nil {
+		limit = func(method, pattern string) web.Middleware { return nil }
+	}
+
+	app.HandlerFuncWithMid(http.MethodGet, group, "/users", api.ListUsersHandler,
+		limit(http.MethodGet, group+"/users"))
+	app.HandlerFuncWithMid(http.MethodPost, group, "/users", api.RegisterUserHandler,
+		limit(http.MethodPost, group+"/users"), web.LimitBody(maxBodyBytes), cfg.Idempotent)
+	app.HandlerFuncWithMid(http.MethodGet, group, "/users/{username}", api.GetUserHandler,
+		limit(http.MethodGet, group+"/users/{username}"))
+	app.HandlerFuncWithMid(http.MethodPatch, group, "/users/{username}", api.UpdateUserHandler,
+		limit(http.MethodPatch, group+"/users/{username}"), web.LimitBody(maxBodyBytes))
+	app.HandlerFuncWithMid(http.MethodDelete, group, "/users/{username}", api.DeleteUserHandler,
+		limit(http.MethodDelete, group+"/users/{username}"))
 }
@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/agkmw/reddit-clone/internal/app/sdk/errs"
+	"github.com/agkmw/reddit-clone/internal/app/domain/userapp"
 	"github.com/agkmw/reddit-clone/internal/database/userdb"
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+	"github.com/agkmw/reddit-clone/internal/platform/validator"
 	"github.com/agkmw/reddit-clone/internal/platform/web"
 	"github.com/google/uuid"
 )
@@ -25,149 +28,224 @@ func (a *api) RegisterUserHandler(ctx context.Context, w http.ResponseWriter, r
 		Username string `json:"username"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Language string `json:"language"`
 	}
 
 	if err := web.Decode(w, r, &input); err != nil {
-		return errs.NewClientError(errs.BadRequest, err, errs.BadRequestMsg)
+		return err
+	}
+
+	if input.Language != "" {
+		web.SetLanguage(ctx, input.Language)
+	}
+
+	v := validator.New()
+
+	v.CheckRule(input.Username != "", "username", validator.RequiredRule())
+	v.CheckRule(validator.MaxChars(input.Username, 32), "username", validator.MaxLengthRule(32))
+	v.CheckRule(input.Email != "", "email", validator.RequiredRule())
+	v.CheckRule(validator.Matches(input.Email, validator.EmailRX), "email", validator.EmailRule())
+	v.CheckRule(validator.MinChars(input.Password, 8), "password", validator.MinLengthRule(8))
+	// bcrypt refuses passwords longer than 72 bytes.
+	v.CheckRule(validator.MaxBytes(input.Password, 72), "password", validator.MaxBytesRule(72))
+	v.CheckRule(input.Language == "" || i18n.Default().Supported(input.Language), "language", validator.PermittedRule())
+
+	if !v.Valid() {
+		return errs.New(errs.FailedValidation, errors.New("invalid registration input"), v.Errors)
 	}
 
-	// TODO: Don't use the db Model to respond back; use app Model;
 	user := userdb.User{
 		ID:       uuid.New(),
 		Username: input.Username,
 		Email:    input.Email,
+		Language: input.Language,
 	}
 
 	if err := user.Password.Set(input.Password); err != nil {
-		// TODO: Refactor the Error package; current approach of creating
-		// errors feels like it needs refactoring...
-		return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+		return errs.NewServerError(errs.Internal, err)
 	}
 
-	if err := a.db.Create(&user); err != nil {
+	if err := a.db.Create(ctx, &user); err != nil {
 		switch {
 		case errors.Is(err, userdb.ErrUsernameAlreadyExists):
-			return errs.NewClientError(errs.AlreadyExists, "user already exists", errs.AlreadyExistsMsg)
+			return errs.NewClientError(errs.AlreadyExists, err, i18n.Message{Key: "user.username_taken"})
 		case errors.Is(err, userdb.ErrEmailAlreadyExists):
-			return errs.NewClientError(errs.EditConflict, "user already exists", errors.New("user already exists"))
+			return errs.NewClientError(errs.AlreadyExists, err, i18n.Message{Key: "user.email_taken"})
 		default:
-			return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+			return errs.Wrap(err, "create user", errs.ErrorInfo{"username": user.Username})
 		}
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, web.Envelope{
+	web.SetETag(w, web.ETag(user.Version))
+
+	return web.Encode(ctx, w, http.StatusOK, web.Envelope{
 		"status": "success",
-		"data":   user,
+		"data":   userapp.ToAppUser(user, userapp.ViewSelf),
 	})
 }
 
 func (a *api) GetUserHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	username := web.ReadParam(r, "username")
 
-	user, err := a.db.GetUserByUsername(username)
+	user, err := a.db.GetUserByUsername(ctx, username)
 	if err != nil {
 		switch {
 		case errors.Is(err, userdb.ErrRecordNotFound):
-			return errs.NewClientError(errs.NotFound, err, errs.NotFoundMsg)
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "user.not_found"})
 		default:
-			return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+			return errs.Wrap(err, "get user", errs.ErrorInfo{"username": username})
 		}
 	}
 
+	etag := web.ETag(user.Version)
+	if web.NotModified(ctx, w, r, etag) {
+		return nil
+	}
+
+	web.SetETag(w, etag)
+
 	env := web.Envelope{
 		"status": "success",
 		"data": map[string]any{
-			"user": user,
+			"user": userapp.ToAppUser(*user, userapp.ViewPublic),
 		},
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, env)
+	return web.Encode(ctx, w, http.StatusOK, env)
 }
 
 func (a *api) UpdateUserHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	username := web.ReadParam(r, "username")
 
-	user, err := a.db.GetUserByUsername(username)
+	user, err := a.db.GetUserByUsername(ctx, username)
 	if err != nil {
 		switch {
 		case errors.Is(err, userdb.ErrRecordNotFound):
-			return errs.NewClientError(errs.NotFound, err, errs.NotFoundMsg)
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "user.not_found"})
 		default:
-			return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+			return errs.Wrap(err, "get user", errs.ErrorInfo{"username": username})
 		}
 	}
 
+	if err := web.CheckIfMatch(r, web.ETag(user.Version)); err != nil {
+		return err
+	}
+
 	var input struct {
 		Username *string `json:"username"`
 		Email    *string `json:"email"`
+		Language *string `json:"language"`
 	}
 
 	if err := web.Decode(w, r, &input); err != nil {
-		return errs.NewClientError(errs.BadRequest, err, errs.BadRequestMsg)
+		return err
 	}
 
+	v := validator.New()
+
 	if input.Username != nil {
 		user.Username = *input.Username
+		v.CheckRule(user.Username != "", "username", validator.RequiredRule())
+		v.CheckRule(validator.MaxChars(user.Username, 32), "username", validator.MaxLengthRule(32))
 	}
 
 	if input.Email != nil {
 		user.Email = *input.Email
+		v.CheckRule(validator.Matches(user.Email, validator.EmailRX), "email", validator.EmailRule())
+	}
+
+	if input.Language != nil {
+		user.Language = *input.Language
+		v.CheckRule(user.Language == "" || i18n.Default().Supported(user.Language), "language", validator.PermittedRule())
+	}
+
+	if !v.Valid() {
+		return errs.New(errs.FailedValidation, errors.New("invalid user update input"), v.Errors)
 	}
 
 	now := time.Now()
 	user.LastLogin = &now
 
-	if err := a.db.UpdateUser(user); err != nil {
+	if err := a.db.UpdateUser(ctx, user); err != nil {
 		switch {
 		case errors.Is(err, userdb.ErrUsernameAlreadyExists):
-			return errs.NewClientError(errs.AlreadyExists, "user already exists", errs.AlreadyExistsMsg)
+			return errs.NewClientError(errs.AlreadyExists, err, i18n.Message{Key: "user.username_taken"})
 		case errors.Is(err, userdb.ErrEmailAlreadyExists):
-			return errs.NewClientError(errs.EditConflict, "user already exists", errors.New("user already exists"))
+			return errs.NewClientError(errs.AlreadyExists, err, i18n.Message{Key: "user.email_taken"})
+		case errors.Is(err, userdb.ErrEditConflict):
+			// Someone else saved between our read and write, so the
+			// version the client matched is no longer current.
+			return errs.New(errs.FailedPrecondition, err, nil)
 		default:
-			return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+			return errs.Wrap(err, "update user", errs.ErrorInfo{"user_id": user.ID})
 		}
 	}
 
+	web.SetETag(w, web.ETag(user.Version))
+
 	env := web.Envelope{
 		"status": "success",
 		"data": map[string]any{
-			"user": user,
+			// Nothing proves yet that the caller is this user, so they
+			// only get the public view back.
+			"user": userapp.ToAppUser(*user, userapp.ViewPublic),
 		},
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, env)
+	return web.Encode(ctx, w, http.StatusOK, env)
 }
 
 func (a *api) DeleteUserHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	username := web.ReadParam(r, "username")
 
-	if err := a.db.DeleteUser(username); err != nil {
+	user, err := a.db.GetUserByUsername(ctx, username)
+	if err != nil {
+		switch {
+		case errors.Is(err, userdb.ErrRecordNotFound):
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "user.not_found"})
+		default:
+			return errs.Wrap(err, "get user", errs.ErrorInfo{"username": username})
+		}
+	}
+
+	if err := web.CheckIfMatch(r, web.ETag(user.Version)); err != nil {
+		return err
+	}
+
+	if err := a.db.DeleteUser(ctx, username, user.Version); err != nil {
 		switch {
+		case errors.Is(err, userdb.ErrEditConflict):
+			return errs.New(errs.FailedPrecondition, err, nil)
 		case errors.Is(err, userdb.ErrRecordNotFound):
-			return errs.NewClientError(errs.NotFound, err, errs.NotFoundMsg)
+			return errs.NewClientError(errs.NotFound, err, i18n.Message{Key: "user.not_found"})
 		default:
-			return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+			return errs.Wrap(err, "delete user", errs.ErrorInfo{"username": username})
 		}
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, web.Envelope{
+	return web.Encode(ctx, w, http.StatusOK, web.Envelope{
 		"status": "success",
 		"data":   "account deleted successfully",
 	})
 }
 
 func (a *api) ListUsersHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	users, err := a.db.GetUsers()
+	users, err := a.db.GetUsers(ctx)
 	if err != nil {
-		return errs.NewServerError(errs.Internal, err, errs.InternalMsg)
+		return errs.Wrap(err, "list users", nil)
 	}
 
+	appUsers := userapp.ToAppUsers(users, userapp.ViewPublic)
+
 	env := web.Envelope{
 		"status": "success",
 		"data": map[string]any{
-			"users": users,
+			"users": appUsers,
 		},
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, env)
+	return web.EncodeAlternates(ctx, w, http.StatusOK, env,
+		web.Alternate{Encoder: web.CSV, Data: userapp.Users(appUsers)},
+		web.Alternate{Encoder: web.NDJSON, Data: appUsers},
+	)
 }
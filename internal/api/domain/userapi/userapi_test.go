@@ -0,0 +1,79 @@
+package userapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agkmw/reddit-clone/internal/api/domain/userapi"
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+func TestRegisterPasswordLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{
+			name:     "too short",
+			password: "short",
+			wantErr:  "must be at least 8 characters long",
+		},
+		{
+			name:     "too many ASCII bytes",
+			password: strings.Repeat("a", 73),
+			wantErr:  "must not be more than 72 bytes long",
+		},
+		{
+			// 30 characters, but 90 bytes in UTF-8.
+			name:     "multibyte characters over 72 bytes",
+			password: strings.Repeat("密", 30),
+			wantErr:  "must not be more than 72 bytes long",
+		},
+	}
+
+	log := logger.New(io.Discard, logger.LevelInfo, "test", func(context.Context) string { return "" })
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.HandleErrors(log))
+
+	// Validation fails before the store is used.
+	userapi.Routes(app, userapi.Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{
+				"username": "gopher",
+				"email":    "gopher@example.com",
+				"password": tt.password,
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(string(body)))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			app.ServeHTTP(w, r)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+			}
+
+			var resp struct {
+				Data map[string]string `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %s", err)
+			}
+
+			if got := resp.Data["password"]; got != tt.wantErr {
+				t.Errorf("password error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
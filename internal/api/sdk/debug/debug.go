@@ -0,0 +1,110 @@
+package debug
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Config struct {
+	Build   string
+	Version string
+	Metrics *metrics.Registry
+	Pool    *pgxpool.Pool
+
+	// Settings returns the live configuration with secrets already redacted.
+	Settings func() map[string]string
+}
+
+var publishOnce sync.Once
+
+// Mux returns the handler for the debug listener. It must never be mounted on
+// the public mux.
+func Mux(cfg Config) *http.ServeMux {
+	publishOnce.Do(func() {
+		expvar.NewString("build").Set(cfg.Build)
+		expvar.NewString("version").Set(cfg.Version)
+		expvar.NewString("go_version").Set(runtime.Version())
+		expvar.Publish("goroutines", expvar.Func(func() any {
+			return runtime.NumGoroutine()
+		}))
+	})
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("GET /debug/goroutines", goroutines)
+	mux.HandleFunc("GET /debug/config", settings(cfg.Settings))
+	mux.HandleFunc("GET /debug/pool", poolStats(cfg.Pool))
+
+	if cfg.Metrics != nil {
+		mux.Handle("GET /metrics", cfg.Metrics.Handler())
+	}
+
+	return mux
+}
+
+func goroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rpprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+func settings(fn func() map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var data map[string]string
+		if fn != nil {
+			data = fn()
+		}
+
+		writeJSON(w, data)
+	}
+}
+
+func poolStats(pool *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if pool == nil {
+			http.Error(w, "no database pool configured", http.StatusNotFound)
+			return
+		}
+
+		s := pool.Stat()
+
+		writeJSON(w, map[string]any{
+			"acquired_conns":             s.AcquiredConns(),
+			"idle_conns":                 s.IdleConns(),
+			"constructing_conns":         s.ConstructingConns(),
+			"total_conns":                s.TotalConns(),
+			"max_conns":                  s.MaxConns(),
+			"acquire_count":              s.AcquireCount(),
+			"acquire_duration":           s.AcquireDuration().String(),
+			"empty_acquire_count":        s.EmptyAcquireCount(),
+			"canceled_acquire_count":     s.CanceledAcquireCount(),
+			"new_conns_count":            s.NewConnsCount(),
+			"max_lifetime_destroy_count": s.MaxLifetimeDestroyCount(),
+			"max_idle_destroy_count":     s.MaxIdleDestroyCount(),
+			"sampled_at":                 time.Now().UTC(),
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.Encode(data)
+}
@@ -0,0 +1,29 @@
+package mid
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+// AdminToken only lets requests through that carry the admin token as a
+// bearer token. It stands in for role checks until users can authenticate.
+func AdminToken(token string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return web.InvalidAuthenticationTokenResponse(ctx, w)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,67 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/agkmw/reddit-clone/internal/database/userdb"
+	"github.com/agkmw/reddit-clone/internal/platform/auth"
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+// UserStore looks up the users Authenticate checks credentials against.
+type UserStore interface {
+	GetUserByUsername(ctx context.Context, username string) (*userdb.User, error)
+}
+
+// Authenticate identifies the caller from HTTP Basic credentials checked
+// against the user store and attaches the identity to the request.
+// Requests without Basic credentials carry on as anonymous, which leaves
+// the admin API's bearer token alone; wrong credentials are refused with a
+// 401. Run it after the API wide rate limit so that guessing is limited.
+func Authenticate(users UserStore) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			username, password, ok := r.BasicAuth()
+			if !ok {
+				return handler(ctx, w, r)
+			}
+
+			user, err := users.GetUserByUsername(ctx, username)
+			if err != nil {
+				if errors.Is(err, userdb.ErrRecordNotFound) {
+					return invalidCredentials(ctx, w)
+				}
+
+				return errs.Wrap(err, "authenticate", errs.ErrorInfo{"username": username})
+			}
+
+			match, err := user.Password.Matches(password)
+			if err != nil {
+				return errs.Wrap(err, "authenticate", errs.ErrorInfo{"username": username})
+			}
+			if !match {
+				return invalidCredentials(ctx, w)
+			}
+
+			ctx = auth.WithIdentity(ctx, auth.Identity{
+				UserID: user.ID.String(),
+				Roles:  user.Roles,
+				Since:  user.CreatedAt,
+			})
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func invalidCredentials(ctx context.Context, w http.ResponseWriter) error {
+	w.Header().Set("WWW-Authenticate", `Basic realm="api", charset="UTF-8"`)
+	return web.InvalidCredentialsResponse(ctx, w)
+}
@@ -0,0 +1,117 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/database/userdb"
+	"github.com/agkmw/reddit-clone/internal/platform/auth"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+	"github.com/google/uuid"
+)
+
+// userStore is a mid.UserStore over a fixed set of users.
+type userStore map[string]*userdb.User
+
+func (s userStore) GetUserByUsername(ctx context.Context, username string) (*userdb.User, error) {
+	if username == "broken" {
+		return nil, errors.New("connection refused")
+	}
+
+	user, ok := s[username]
+	if !ok {
+		return nil, userdb.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+func newUser(t *testing.T, username, password string, roles ...string) *userdb.User {
+	t.Helper()
+
+	user := userdb.User{ID: uuid.New(), Username: username, Roles: roles}
+	if err := user.Password.Set(password); err != nil {
+		t.Fatalf("setting password: %s", err)
+	}
+
+	return &user
+}
+
+// newTestApp serves GET /whoami, which answers with the caller's user id
+// and roles, behind the middleware given.
+func newTestApp(mw ...web.Middleware) *web.App {
+	log := logger.New(io.Discard, logger.LevelInfo, "test", func(context.Context) string { return "" })
+
+	mw = append([]web.Middleware{mid.HandleErrors(log)}, mw...)
+	app := web.NewApp(func(context.Context, string, ...any) {}, mw...)
+
+	app.HandlerFunc(http.MethodGet, "", "/whoami", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		id := auth.GetIdentity(ctx)
+		return web.Encode(ctx, w, http.StatusOK, web.Envelope{"user": id.UserID, "roles": id.Roles})
+	})
+
+	return app
+}
+
+func TestAuthenticate(t *testing.T) {
+	mod := newUser(t, "mod", "correct horse battery", "moderator")
+
+	app := newTestApp(mid.Authenticate(userStore{"mod": mod}))
+
+	tests := []struct {
+		name       string
+		header     string
+		basic      [2]string
+		wantStatus int
+		wantUser   string
+	}{
+		{name: "anonymous", wantStatus: http.StatusOK},
+		{name: "bearer token is left alone", header: "Bearer admin-token", wantStatus: http.StatusOK},
+		{name: "valid credentials", basic: [2]string{"mod", "correct horse battery"}, wantStatus: http.StatusOK, wantUser: mod.ID.String()},
+		{name: "wrong password", basic: [2]string{"mod", "wrong"}, wantStatus: http.StatusUnauthorized},
+		{name: "unknown user", basic: [2]string{"nobody", "correct horse battery"}, wantStatus: http.StatusUnauthorized},
+		{name: "store failure", basic: [2]string{"broken", "x"}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.basic[0] != "" {
+				r.SetBasicAuth(tt.basic[0], tt.basic[1])
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			want := `"user":"` + tt.wantUser + `"`
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("body = %s, want %s", w.Body, want)
+			}
+			if tt.wantUser != "" && !strings.Contains(w.Body.String(), `"roles":["moderator"]`) {
+				t.Errorf("body = %s, want the moderator role", w.Body)
+			}
+		})
+	}
+}
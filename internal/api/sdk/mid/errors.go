@@ -0,0 +1,51 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+func HandleErrors(log *logger.Logger) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			err := mid.Errors(ctx, log, hdl)
+			if err == nil {
+				return nil
+			}
+
+			return errorResponse(ctx, w, err)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func errorResponse(ctx context.Context, w http.ResponseWriter, err error) error {
+	e, ok := errs.Get(err)
+	if !ok {
+		return web.ServerErrorResponse(ctx, w)
+	}
+
+	switch e.Type() {
+	case errs.Internal, errs.Unknown:
+		return web.ServerErrorResponse(ctx, w)
+
+	default:
+		if msg, ok := e.ClientMessage(); ok {
+			return web.LocalizedErrorResponse(ctx, w, e.Type(), msg, e.Data())
+		}
+
+		return web.TypeErrorResponse(ctx, w, e.Type(), e.Data())
+	}
+}
@@ -0,0 +1,20 @@
+package mid
+
+import (
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+// Idempotency scopes Idempotency-Key values to the authenticated user, so
+// that users cannot see each other's responses. Anonymous requests, such as
+// registrations, are scoped to the client IP: clients sharing an address
+// must send keys that are unique between them, such as random UUIDs. It does
+// nothing without a store.
+func Idempotency(cfg web.IdempotencyConfig) web.Middleware {
+	if cfg.Store == nil {
+		return nil
+	}
+
+	cfg.Scope = web.KeyByUser(userID)
+
+	return web.Idempotency(cfg)
+}
@@ -0,0 +1,126 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/auth"
+	"github.com/agkmw/reddit-clone/internal/platform/idempotency"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+// memoryStore is an idempotency.Store for a single process.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[[2]string]idempotency.Record
+}
+
+func (s *memoryStore) Claim(ctx context.Context, scope, key, fingerprint string, lease, ttl time.Duration) (idempotency.Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[[2]string{scope, key}]; ok {
+		return rec, false, nil
+	}
+
+	rec := idempotency.Record{Fingerprint: fingerprint}
+	s.records[[2]string{scope, key}] = rec
+
+	return rec, true, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, scope, key string, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[[2]string{scope, key}] = rec
+
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, [2]string{scope, key})
+
+	return nil
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	// Stands in for Authenticate.
+	identify := func(handler web.Handler) web.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if user := r.Header.Get("X-User"); user != "" {
+				ctx = auth.WithIdentity(ctx, auth.Identity{UserID: user})
+			}
+			return handler(ctx, w, r)
+		}
+	}
+
+	var calls int
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, identify)
+	app.HandlerFuncWithMid(http.MethodPost, "", "/things", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		calls++
+		return web.Encode(ctx, w, http.StatusCreated, web.Envelope{"user": auth.GetIdentity(ctx).UserID})
+	}, mid.Idempotency(web.IdempotencyConfig{
+		Store: &memoryStore{records: make(map[[2]string]idempotency.Record)},
+	}))
+
+	// Every request sends the same key and body; only who sends it varies.
+	tests := []struct {
+		name         string
+		user         string
+		ip           string
+		wantReplayed bool
+		wantUser     string
+	}{
+		{name: "alice", user: "alice", ip: "192.0.2.1", wantUser: "alice"},
+		{name: "alice retrying from another address", user: "alice", ip: "198.51.100.7", wantReplayed: true, wantUser: "alice"},
+		{name: "bob behind the same address", user: "bob", ip: "192.0.2.1", wantUser: "bob"},
+		{name: "anonymous", ip: "192.0.2.1"},
+		{name: "anonymous from the same address", ip: "192.0.2.1", wantReplayed: true},
+		{name: "anonymous from another address", ip: "198.51.100.7"},
+	}
+
+	wantCalls := 0
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{"a":1}`))
+		r.RemoteAddr = tt.ip + ":1234"
+		r.Header.Set("Idempotency-Key", "same-key")
+		if tt.user != "" {
+			r.Header.Set("X-User", tt.user)
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, w.Code, http.StatusCreated, w.Body)
+		}
+
+		replayed := w.Header().Get("Idempotent-Replayed") == "true"
+		if replayed != tt.wantReplayed {
+			t.Errorf("%s: replayed = %t, want %t", tt.name, replayed, tt.wantReplayed)
+		}
+
+		if want := `"user":"` + tt.wantUser + `"`; !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: body = %s, want %s", tt.name, w.Body, want)
+		}
+
+		if !tt.wantReplayed {
+			wantCalls++
+		}
+		if calls != wantCalls {
+			t.Fatalf("%s: handler ran %d times, want %d", tt.name, calls, wantCalls)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+func HandleLogs(log *logger.Logger) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.Logs(ctx, log, hdl, web.GetClientIP(ctx).String(), r.Method, r.URL.Path, r.URL.RawQuery)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,25 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/agkmw/reddit-clone/internal/platform/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+func RecoverPanics() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.Panics(ctx, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,31 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+type LimiterConfig struct {
+	Limiter  ratelimit.Limiter
+	Limit    func(ctx context.Context) (ratelimit.Limit, bool)
+	Routes   ratelimit.Table
+	Enabled  func() bool
+	OnReject func(ctx context.Context)
+	OnError  func(ctx context.Context, err error)
+}
+
+// RateLimit applies the API wide limit, counted per client IP across all
+// routes.
+func RateLimit(cfg LimiterConfig) web.Middleware {
+	return web.RateLimit(web.RateLimitConfig{
+		Limiter: cfg.Limiter,
+		Policy: web.RateLimitPolicy{
+			Limit: cfg.Limit,
+			Key:   web.KeyByIP,
+		},
+		OnReject: cfg.OnReject,
+		OnError:  cfg.OnError,
+	})
+}
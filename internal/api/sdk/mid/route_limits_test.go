@@ -0,0 +1,122 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/platform/auth"
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+func TestRouteLimitsByTier(t *testing.T) {
+	table, err := ratelimit.ParseTable(mid.DefaultRouteLimits)
+	if err != nil {
+		t.Fatalf("ParseTable: %s", err)
+	}
+
+	limits := mid.NewRouteLimits(mid.RouteLimitConfig{
+		Limiter: ratelimit.NewMemory(),
+		Table:   table,
+	})
+
+	tests := []struct {
+		name string
+		id   auth.Identity
+
+		// want is how many of 12 updates in a row go through.
+		want int
+	}{
+		{
+			name: "anonymous",
+			want: 5,
+		},
+		{
+			name: "new account",
+			id:   auth.Identity{UserID: "new", Since: time.Now().Add(-time.Hour)},
+			want: 10,
+		},
+		{
+			name: "established by account age",
+			id:   auth.Identity{UserID: "old", Since: time.Now().Add(-30 * 24 * time.Hour)},
+			want: 12,
+		},
+		{
+			name: "new account with the moderator role",
+			id:   auth.Identity{UserID: "mod", Roles: []string{"moderator"}, Since: time.Now().Add(-time.Hour)},
+			want: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Stands in for Authenticate.
+			identify := func(handler web.Handler) web.Handler {
+				return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+					if !tt.id.Anonymous() {
+						ctx = auth.WithIdentity(ctx, tt.id)
+					}
+					return handler(ctx, w, r)
+				}
+			}
+
+			app := web.NewApp(func(context.Context, string, ...any) {}, identify)
+
+			const pattern = "/v1/users/{username}"
+			app.HandlerFuncWithMid(http.MethodPatch, "", pattern, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}, limits.For(http.MethodPatch, pattern))
+
+			var allowed int
+			for range 12 {
+				r := httptest.NewRequest(http.MethodPatch, "/v1/users/gopher", nil)
+				w := httptest.NewRecorder()
+				app.ServeHTTP(w, r)
+
+				switch w.Code {
+				case http.StatusNoContent:
+					allowed++
+				case http.StatusTooManyRequests:
+				default:
+					t.Fatalf("status = %d: %s", w.Code, w.Body)
+				}
+			}
+
+			if allowed != tt.want {
+				t.Errorf("%d of 12 updates allowed, want %d", allowed, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserTier(t *testing.T) {
+	const establishedAfter = 7 * 24 * time.Hour
+
+	tests := []struct {
+		name string
+		id   auth.Identity
+		want ratelimit.Tier
+	}{
+		{name: "anonymous", want: ratelimit.TierAnonymous},
+		{name: "unknown account age", id: auth.Identity{UserID: "u"}, want: ratelimit.TierNew},
+		{name: "young account", id: auth.Identity{UserID: "u", Since: time.Now().Add(-establishedAfter + time.Hour)}, want: ratelimit.TierNew},
+		{name: "old account", id: auth.Identity{UserID: "u", Since: time.Now().Add(-establishedAfter)}, want: ratelimit.TierEstablished},
+		{name: "established role", id: auth.Identity{UserID: "u", Roles: []string{"established"}}, want: ratelimit.TierEstablished},
+		{name: "moderator role", id: auth.Identity{UserID: "u", Roles: []string{"moderator"}}, want: ratelimit.TierModerator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.WithIdentity(context.Background(), tt.id)
+
+			if got := mid.UserTier(ctx, establishedAfter); got != tt.want {
+				t.Errorf("UserTier = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
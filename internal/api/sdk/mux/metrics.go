@@ -0,0 +1,49 @@
+package mux
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/metrics"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+type httpMetrics struct {
+	requests   *metrics.CounterVec
+	latency    *metrics.HistogramVec
+	rejections *metrics.Counter
+}
+
+func newHTTPMetrics(reg *metrics.Registry) *httpMetrics {
+	return &httpMetrics{
+		requests: reg.NewCounterVec(
+			"http_requests_total",
+			"Total number of HTTP requests by route pattern and status.",
+			"method", "route", "status",
+		),
+		latency: reg.NewHistogramVec(
+			"http_request_duration_seconds",
+			"HTTP request latency by route pattern and status.",
+			metrics.DefaultBuckets,
+			"method", "route", "status",
+		),
+		rejections: reg.NewCounter(
+			"http_rate_limit_rejections_total",
+			"Total number of requests rejected by the rate limiter.",
+		),
+	}
+}
+
+func (m *httpMetrics) observe() web.MetricsFn {
+	return func(ctx context.Context, method, route string, status int, took time.Duration) {
+		code := strconv.Itoa(status)
+
+		m.requests.With(method, route, code).Inc()
+		m.latency.With(method, route, code).Observe(took.Seconds())
+	}
+}
+
+func (m *httpMetrics) rejected(ctx context.Context) {
+	m.rejections.Inc()
+}
@@ -3,11 +3,16 @@ package mux
 import (
 	"context"
 
+	"github.com/agkmw/reddit-clone/internal/api/domain/flagapi"
 	"github.com/agkmw/reddit-clone/internal/api/domain/healthcheckapi"
 	"github.com/agkmw/reddit-clone/internal/api/domain/userapi"
 	"github.com/agkmw/reddit-clone/internal/api/sdk/mid"
+	"github.com/agkmw/reddit-clone/internal/database/flagdb"
 	"github.com/agkmw/reddit-clone/internal/database/userdb"
+	"github.com/agkmw/reddit-clone/internal/platform/feature"
 	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/metrics"
+	"github.com/agkmw/reddit-clone/internal/platform/trace"
 	"github.com/agkmw/reddit-clone/internal/platform/web"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -17,8 +22,25 @@ type Config struct {
 	Version     string
 	Build       string
 	Limiter     mid.LimiterConfig
+	Problem     web.ProblemConfig
 	Pool        *pgxpool.Pool
 	Log         *logger.Logger
+	Metrics     *metrics.Registry
+	Tracing     *trace.Provider
+	Draining    func() bool
+	Flags       *feature.Flags
+	ClientIP    *web.IPResolver
+	CORS        *web.CORS
+	Compression web.CompressionConfig
+	Idempotency web.IdempotencyConfig
+	Security    web.SecurityConfig
+
+	// MaxBodyBytes limits request bodies on routes that set no limit of
+	// their own.
+	MaxBodyBytes int64
+
+	// AdminToken guards the admin API, which is not mounted when empty.
+	AdminToken string
 }
 
 func WebAPI(cfg Config) *web.App {
@@ -26,31 +48,89 @@ func WebAPI(cfg Config) *web.App {
 		cfg.Log.Info(ctx, msg, args...)
 	}
 
+	var m *httpMetrics
+	if cfg.Metrics != nil {
+		m = newHTTPMetrics(cfg.Metrics)
+		cfg.Limiter.OnReject = m.rejected
+	}
+
+	cfg.Limiter.OnError = func(ctx context.Context, err error) {
+		cfg.Log.Error(ctx, "rate limiter failed, letting request through", "error", err)
+	}
+
 	app := web.NewApp(
 		logFn,
 		mid.HandleLogs(cfg.Log),
 		mid.HandleErrors(cfg.Log),
 		mid.RecoverPanics(),
 		mid.RateLimit(cfg.Limiter),
+		mid.Authenticate(userdb.New(cfg.Pool)),
+		web.RequireJSON(),
+		web.LimitBody(cfg.MaxBodyBytes),
 	)
 
+	app.ProblemDetails(cfg.Problem)
+	app.Tracing(cfg.Tracing)
+
+	if cfg.ClientIP != nil {
+		app.ClientIP(cfg.ClientIP)
+	}
+
+	if cfg.CORS != nil {
+		app.CORS(cfg.CORS)
+	}
+
+	app.Compression(cfg.Compression)
+	app.Security(cfg.Security)
+
+	if m != nil {
+		app.Metrics(m.observe())
+	}
+
 	RouteAdder(cfg, app)
 
 	return app
 }
 
 func RouteAdder(cfg Config, app *web.App) {
+	limits := mid.NewRouteLimits(mid.RouteLimitConfig{
+		Limiter:  cfg.Limiter.Limiter,
+		Table:    cfg.Limiter.Routes,
+		Enabled:  cfg.Limiter.Enabled,
+		OnReject: cfg.Limiter.OnReject,
+		OnError:  cfg.Limiter.OnError,
+	})
+
 	userapi.Routes(
 		app,
-		userdb.New(cfg.Pool),
+		userapi.Config{
+			Store:      userdb.New(cfg.Pool),
+			Limit:      limits.For,
+			Idempotent: mid.Idempotency(cfg.Idempotency),
+		},
 	)
 
+	if cfg.AdminToken != "" {
+		flagapi.Routes(
+			app,
+			flagapi.Config{
+				Store: flagdb.New(cfg.Pool),
+				Flags: cfg.Flags,
+				Log:   cfg.Log,
+				Auth:  mid.AdminToken(cfg.AdminToken),
+			},
+		)
+	}
+
 	healthcheckapi.Routes(
 		app,
 		healthcheckapi.Config{
 			Environment: cfg.Environment,
 			Version:     cfg.Version,
 			Build:       cfg.Build,
+			Log:         cfg.Log,
+			Pool:        cfg.Pool,
+			Draining:    cfg.Draining,
 		},
 	)
 }
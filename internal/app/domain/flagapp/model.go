@@ -0,0 +1,97 @@
+package flagapp
+
+import (
+	"context"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/database/flagdb"
+	"github.com/agkmw/reddit-clone/internal/platform/feature"
+)
+
+type Override struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Enabled bool   `json:"enabled"`
+}
+
+type Flag struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Enabled     bool       `json:"enabled"`
+	Rollout     int        `json:"rollout"`
+	Overrides   []Override `json:"overrides"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int        `json:"version"`
+}
+
+func ToAppFlag(flag flagdb.Flag) Flag {
+	overrides := make([]Override, len(flag.Overrides))
+	for i, o := range flag.Overrides {
+		overrides[i] = Override{
+			Kind:    o.Kind,
+			Subject: o.Subject,
+			Enabled: o.Enabled,
+		}
+	}
+
+	return Flag{
+		Name:        flag.Name,
+		Description: flag.Description,
+		Enabled:     flag.Enabled,
+		Rollout:     flag.Rollout,
+		Overrides:   overrides,
+		CreatedAt:   flag.CreatedAt,
+		UpdatedAt:   flag.UpdatedAt,
+		Version:     flag.Version,
+	}
+}
+
+func ToAppFlags(flags []flagdb.Flag) []Flag {
+	app := make([]Flag, len(flags))
+	for i, flag := range flags {
+		app[i] = ToAppFlag(flag)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+func ToFeatureFlag(flag flagdb.Flag) feature.Flag {
+	f := feature.Flag{
+		Name:    flag.Name,
+		Enabled: flag.Enabled,
+		Rollout: flag.Rollout,
+		Users:   make(map[string]bool),
+		Roles:   make(map[string]bool),
+	}
+
+	for _, o := range flag.Overrides {
+		switch o.Kind {
+		case flagdb.OverrideUser:
+			f.Users[o.Subject] = o.Enabled
+		case flagdb.OverrideRole:
+			f.Roles[o.Subject] = o.Enabled
+		}
+	}
+
+	return f
+}
+
+// Loader reads every flag from the store for the feature flag cache.
+func Loader(store *flagdb.Store) feature.LoadFn {
+	return func(ctx context.Context) ([]feature.Flag, error) {
+		flags, err := store.GetFlags(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		f := make([]feature.Flag, len(flags))
+		for i, flag := range flags {
+			f[i] = ToFeatureFlag(flag)
+		}
+
+		return f, nil
+	}
+}
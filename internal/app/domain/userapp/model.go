@@ -0,0 +1,94 @@
+package userapp
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/database/userdb"
+)
+
+// View selects which fields of a user the caller is allowed to see.
+type View int
+
+const (
+	ViewPublic View = iota
+	ViewSelf
+	ViewAdmin
+)
+
+// User is the representation of a user returned to clients. It must never
+// carry credentials; new fields are opt-in per view in ToAppUser.
+type User struct {
+	ID        string     `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email,omitempty"`
+	Language  string     `json:"language,omitempty"`
+	Activated *bool      `json:"activated,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastLogin *time.Time `json:"last_login,omitempty"`
+}
+
+func ToAppUser(usr userdb.User, view View) User {
+	user := User{
+		ID:        usr.ID.String(),
+		Username:  usr.Username,
+		CreatedAt: usr.CreatedAt,
+	}
+
+	if view == ViewSelf || view == ViewAdmin {
+		activated := usr.Activated
+
+		user.Email = usr.Email
+		user.Language = usr.Language
+		user.Activated = &activated
+	}
+
+	// Login activity is for moderation, not something the user needs
+	// echoed back.
+	if view == ViewAdmin {
+		user.LastLogin = usr.LastLogin
+	}
+
+	return user
+}
+
+func ToAppUsers(usrs []*userdb.User, view View) []User {
+	users := make([]User, len(usrs))
+	for i, usr := range usrs {
+		users[i] = ToAppUser(*usr, view)
+	}
+
+	return users
+}
+
+// Users is a list of users that can also be sent as CSV. Fields the view
+// hides are left empty.
+type Users []User
+
+func (us Users) MarshalCSV() ([][]string, error) {
+	records := [][]string{
+		{"id", "username", "email", "language", "activated", "created_at", "last_login"},
+	}
+
+	for _, u := range us {
+		var activated, lastLogin string
+		if u.Activated != nil {
+			activated = strconv.FormatBool(*u.Activated)
+		}
+		if u.LastLogin != nil {
+			lastLogin = u.LastLogin.Format(time.RFC3339)
+		}
+
+		records = append(records, []string{
+			u.ID,
+			u.Username,
+			u.Email,
+			u.Language,
+			activated,
+			u.CreatedAt.Format(time.RFC3339),
+			lastLogin,
+		})
+	}
+
+	return records, nil
+}
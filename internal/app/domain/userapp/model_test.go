@@ -0,0 +1,112 @@
+package userapp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/database/userdb"
+	"github.com/google/uuid"
+)
+
+func TestToAppUserDoesNotLeakSecrets(t *testing.T) {
+	lastLogin := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	usr := userdb.User{
+		ID:        uuid.New(),
+		Username:  "gopher",
+		Email:     "gopher@example.com",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		LastLogin: &lastLogin,
+		Activated: true,
+		Language:  "es",
+		Version:   7,
+	}
+	if err := usr.Password.Set("correct horse battery"); err != nil {
+		t.Fatalf("setting password: %v", err)
+	}
+
+	// Encoding the database user itself must not carry the password
+	// either, so that the views are not the only safeguard.
+	raw, err := json.Marshal(usr)
+	if err != nil {
+		t.Fatalf("marshal database user: %v", err)
+	}
+	if strings.Contains(strings.ToLower(string(raw)), "password") || strings.Contains(string(raw), "JDJh") {
+		t.Errorf("database user encodes its password: %s", raw)
+	}
+
+	tests := []struct {
+		name    string
+		view    View
+		want    []string
+		private []string
+	}{
+		{
+			name:    "public",
+			view:    ViewPublic,
+			want:    []string{"id", "username", "created_at"},
+			private: []string{"email", "language", "activated", "last_login"},
+		},
+		{
+			name:    "self",
+			view:    ViewSelf,
+			want:    []string{"id", "username", "created_at", "email", "language", "activated"},
+			private: []string{"last_login"},
+		},
+		{
+			name: "admin",
+			view: ViewAdmin,
+			want: []string{"id", "username", "created_at", "email", "language", "activated", "last_login"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(ToAppUser(usr, tt.view))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			for key := range got {
+				k := strings.ToLower(key)
+				for _, secret := range []string{"password", "hash", "version"} {
+					if strings.Contains(k, secret) {
+						t.Errorf("key %q leaks %s: %s", key, secret, b)
+					}
+				}
+			}
+
+			if strings.Contains(string(b), "correct horse battery") {
+				t.Errorf("plaintext password in output: %s", b)
+			}
+
+			// bcrypt hashes start with $2a$, which base64 encodes to JDJh.
+			if strings.Contains(string(b), "$2a$") || strings.Contains(string(b), "JDJh") {
+				t.Errorf("password hash in output: %s", b)
+			}
+
+			for _, key := range tt.want {
+				if _, ok := got[key]; !ok {
+					t.Errorf("missing key %q: %s", key, b)
+				}
+			}
+
+			for _, key := range tt.private {
+				if _, ok := got[key]; ok {
+					t.Errorf("key %q is not part of the %s view: %s", key, tt.name, b)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d keys, want %d: %s", len(got), len(tt.want), b)
+			}
+		})
+	}
+}
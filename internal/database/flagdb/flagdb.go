@@ -0,0 +1,311 @@
+package flagdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	UniqueViolation     = "23505"
+	ForeignKeyViolation = "23503"
+)
+
+var (
+	ErrFlagAlreadyExists = errors.New("feature flag already exists")
+	ErrRecordNotFound    = errors.New("record not found")
+	ErrEditConflict      = errors.New("edit conflict")
+)
+
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+func (s *Store) Create(ctx context.Context, flag *Flag) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO
+			feature_flags (name, description, enabled, rollout)
+		VALUES
+			($1, $2, $3, $4)
+		RETURNING
+			created_at, updated_at, version
+	`
+
+	args := []any{flag.Name, flag.Description, flag.Enabled, flag.Rollout}
+
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&flag.CreatedAt, &flag.UpdatedAt, &flag.Version)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == UniqueViolation:
+			return ErrFlagAlreadyExists
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) Update(ctx context.Context, flag *Flag) error {
+	query := `
+		UPDATE
+			feature_flags
+		SET
+			description = $1,
+			enabled     = $2,
+			rollout     = $3,
+			updated_at  = now(),
+			version     = version + 1
+		WHERE
+			name = $4
+		AND
+			version = $5
+		RETURNING
+			updated_at, version
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	args := []any{flag.Description, flag.Enabled, flag.Rollout, flag.Name, flag.Version}
+
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&flag.UpdatedAt, &flag.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, name string) error {
+	query := `
+		DELETE FROM
+			feature_flags
+		WHERE
+			name = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := s.pool.Exec(ctx, query, name)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *Store) GetFlag(ctx context.Context, name string) (*Flag, error) {
+	query := `
+		SELECT
+			name, description, enabled, rollout, created_at, updated_at, version
+		FROM
+			feature_flags
+		WHERE
+			name = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var flag Flag
+
+	err := s.pool.QueryRow(ctx, query, name).Scan(
+		&flag.Name,
+		&flag.Description,
+		&flag.Enabled,
+		&flag.Rollout,
+		&flag.CreatedAt,
+		&flag.UpdatedAt,
+		&flag.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	overrides, err := s.getOverrides(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	flag.Overrides = overrides[name]
+
+	return &flag, nil
+}
+
+// GetFlags returns every flag with its overrides, ordered by name.
+func (s *Store) GetFlags(ctx context.Context) ([]Flag, error) {
+	query := `
+		SELECT
+			name, description, enabled, rollout, created_at, updated_at, version
+		FROM
+			feature_flags
+		ORDER BY
+			name ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := make([]Flag, 0)
+
+	for rows.Next() {
+		var flag Flag
+
+		err := rows.Scan(
+			&flag.Name,
+			&flag.Description,
+			&flag.Enabled,
+			&flag.Rollout,
+			&flag.CreatedAt,
+			&flag.UpdatedAt,
+			&flag.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.getOverrides(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range flags {
+		flags[i].Overrides = overrides[flags[i].Name]
+	}
+
+	return flags, nil
+}
+
+// SetOverride creates or replaces the override for one subject.
+func (s *Store) SetOverride(ctx context.Context, name string, o Override) error {
+	query := `
+		INSERT INTO
+			feature_flag_overrides (flag_name, kind, subject, enabled)
+		VALUES
+			($1, $2, $3, $4)
+		ON CONFLICT (flag_name, kind, subject) DO UPDATE SET
+			enabled = EXCLUDED.enabled
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := s.pool.Exec(ctx, query, name, o.Kind, o.Subject, o.Enabled)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == ForeignKeyViolation:
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteOverride(ctx context.Context, name, kind, subject string) error {
+	query := `
+		DELETE FROM
+			feature_flag_overrides
+		WHERE
+			flag_name = $1 AND kind = $2 AND subject = $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := s.pool.Exec(ctx, query, name, kind, subject)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// getOverrides returns overrides keyed by flag name, for one flag or for all
+// of them when name is empty.
+func (s *Store) getOverrides(ctx context.Context, name string) (map[string][]Override, error) {
+	query := `
+		SELECT
+			flag_name, kind, subject, enabled
+		FROM
+			feature_flag_overrides
+		WHERE
+			$1 = '' OR flag_name = $1
+		ORDER BY
+			flag_name, kind, subject
+	`
+
+	rows, err := s.pool.Query(ctx, query, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make(map[string][]Override)
+
+	for rows.Next() {
+		var flagName string
+		var o Override
+
+		if err := rows.Scan(&flagName, &o.Kind, &o.Subject, &o.Enabled); err != nil {
+			return nil, err
+		}
+
+		overrides[flagName] = append(overrides[flagName], o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
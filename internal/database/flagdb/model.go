@@ -0,0 +1,27 @@
+package flagdb
+
+import "time"
+
+const (
+	OverrideUser = "user"
+	OverrideRole = "role"
+)
+
+type Flag struct {
+	Name        string
+	Description string
+	Enabled     bool
+	Rollout     int
+	Overrides   []Override
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int
+}
+
+// Override forces a flag on or off for one user ID or role, regardless of
+// the flag's own state.
+type Override struct {
+	Kind    string
+	Subject string
+	Enabled bool
+}
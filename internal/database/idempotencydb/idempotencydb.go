@@ -0,0 +1,175 @@
+package idempotencydb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/idempotency"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store is an idempotency.Store shared by every replica using the database.
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+func (s *Store) Claim(
+	ctx context.Context,
+	scope, key, fingerprint string,
+	lease, ttl time.Duration,
+) (idempotency.Record, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	// A new key is inserted. An existing one is only taken over once it has
+	// expired or its owner has given up holding it without finishing.
+	query := `
+		INSERT INTO
+			idempotency_keys (scope, key, fingerprint, locked_until, expires_at)
+		VALUES
+			($1, $2, $3, now() + make_interval(secs => $4), now() + make_interval(secs => $5))
+		ON CONFLICT (scope, key) DO UPDATE
+		SET
+			fingerprint  = EXCLUDED.fingerprint,
+			status       = NULL,
+			header       = NULL,
+			body         = NULL,
+			locked_until = EXCLUDED.locked_until,
+			created_at   = now(),
+			expires_at   = EXCLUDED.expires_at
+		WHERE
+			idempotency_keys.expires_at < now()
+		OR
+			(idempotency_keys.status IS NULL AND idempotency_keys.locked_until < now())
+		RETURNING
+			true
+	`
+
+	args := []any{scope, key, fingerprint, lease.Seconds(), ttl.Seconds()}
+
+	var claimed bool
+
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&claimed)
+	switch {
+	case err == nil:
+		return idempotency.Record{Fingerprint: fingerprint}, true, nil
+
+	case !errors.Is(err, pgx.ErrNoRows):
+		return idempotency.Record{}, false, err
+	}
+
+	query = `
+		SELECT
+			fingerprint, status, header, body
+		FROM
+			idempotency_keys
+		WHERE
+			scope = $1
+		AND
+			key = $2
+	`
+
+	var rec idempotency.Record
+	var status *int
+	var header http.Header
+
+	err = s.pool.QueryRow(ctx, query, scope, key).Scan(&rec.Fingerprint, &status, &header, &rec.Body)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Released between the two statements; report it as still
+			// running so the caller tries to claim it again.
+			return idempotency.Record{Fingerprint: fingerprint}, false, nil
+		}
+
+		return idempotency.Record{}, false, err
+	}
+
+	if status != nil {
+		rec.Done = true
+		rec.Status = *status
+		rec.Header = header
+	}
+
+	return rec, false, nil
+}
+
+func (s *Store) Complete(ctx context.Context, scope, key string, rec idempotency.Record) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE
+			idempotency_keys
+		SET
+			status       = $3,
+			header       = $4,
+			body         = $5,
+			locked_until = NULL
+		WHERE
+			scope = $1
+		AND
+			key = $2
+	`
+
+	_, err := s.pool.Exec(ctx, query, scope, key, rec.Status, rec.Header, rec.Body)
+
+	return err
+}
+
+func (s *Store) Release(ctx context.Context, scope, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM
+			idempotency_keys
+		WHERE
+			scope = $1
+		AND
+			key = $2
+		AND
+			status IS NULL
+	`
+
+	_, err := s.pool.Exec(ctx, query, scope, key)
+
+	return err
+}
+
+// Run deletes expired keys every ten minutes until ctx is canceled.
+func (s *Store) Run(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			s.evict(ctx)
+		}
+	}
+}
+
+func (s *Store) evict(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM
+			idempotency_keys
+		WHERE
+			expires_at < now()
+	`
+
+	// A failed sweep only leaves expired rows behind for the next one.
+	s.pool.Exec(ctx, query)
+}
@@ -0,0 +1,107 @@
+package ratelimitdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store is a ratelimit.Limiter shared by every replica using the database.
+// The clock is the database's, so replicas with skewed clocks still agree.
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+func (s *Store) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	// The update only happens when the request is allowed, which keeps the
+	// check and the write in a single atomic statement.
+	query := `
+		INSERT INTO
+			rate_limits (key, tat)
+		VALUES
+			($1, now() + make_interval(secs => $2))
+		ON CONFLICT (key) DO UPDATE
+		SET
+			tat = GREATEST(rate_limits.tat, now()) + make_interval(secs => $2)
+		WHERE
+			GREATEST(rate_limits.tat, now()) + make_interval(secs => $2) - make_interval(secs => $3) <= now()
+		RETURNING
+			tat, now()
+	`
+
+	interval := limit.Interval()
+	args := []any{key, interval.Seconds(), limit.Tolerance().Seconds()}
+
+	var tat, now time.Time
+
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&tat, &now)
+	switch {
+	case err == nil:
+		_, res := limit.Check(tat.Add(-interval), now)
+		return res, nil
+
+	case !errors.Is(err, pgx.ErrNoRows):
+		return ratelimit.Result{}, err
+	}
+
+	// Rejected. Read the stored TAT back to tell the client when to retry.
+	query = `
+		SELECT
+			tat, now()
+		FROM
+			rate_limits
+		WHERE
+			key = $1
+	`
+
+	if err := s.pool.QueryRow(ctx, query, key).Scan(&tat, &now); err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	_, res := limit.Check(tat, now)
+	res.Allowed = false
+
+	return res, nil
+}
+
+// Run deletes keys whose TAT has passed every minute until ctx is canceled.
+func (s *Store) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			s.evict(ctx)
+		}
+	}
+}
+
+func (s *Store) evict(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM
+			rate_limits
+		WHERE
+			tat < now()
+	`
+
+	// A failed sweep only leaves stale rows behind for the next one.
+	s.pool.Exec(ctx, query)
+}
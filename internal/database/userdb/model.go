@@ -9,14 +9,19 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID  `json:"id"`
-	Username  string     `json:"username"`
-	Email     string     `json:"email"`
-	Password  password   `json:"password"`
-	CreatedAt time.Time  `json:"created_at"`
-	LastLogin *time.Time `json:"last_login"`
-	Activated bool       `json:"activated"`
-	Version   int        `json:"-"`
+	ID        uuid.UUID
+	Username  string
+	Email     string
+	Password  password `json:"-"`
+	CreatedAt time.Time
+	LastLogin *time.Time
+	Activated bool
+	Language  string
+
+	// Roles are granted by operators in the database, e.g. moderator.
+	Roles []string
+
+	Version int
 }
 
 type password struct {
@@ -16,6 +16,7 @@ var (
 	ErrUsernameAlreadyExists = errors.New("username already exists")
 	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrRecordNotFound        = errors.New("record not found")
+	ErrEditConflict          = errors.New("edit conflict")
 )
 
 type Store struct {
@@ -26,20 +27,20 @@ func New(pool *pgxpool.Pool) *Store {
 	return &Store{pool: pool}
 }
 
-func (s *Store) Create(user *User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (s *Store) Create(ctx context.Context, user *User) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `
 		INSERT INTO 
-			users (id, username, email, password_hash, activated)
+			users (id, username, email, password_hash, activated, language)
 		VALUES
-			($1, $2, $3, $4, $5)
+			($1, $2, $3, $4, $5, NULLIF($6, ''))
 		RETURNING 
 			created_at, version
 	`
 
-	args := []any{user.ID, user.Username, user.Email, user.Password.hash, user.Activated}
+	args := []any{user.ID, user.Username, user.Email, user.Password.hash, user.Activated, user.Language}
 
 	err := s.pool.QueryRow(ctx, query, args...).Scan(&user.CreatedAt, &user.Version)
 	if err != nil {
@@ -52,6 +53,7 @@ func (s *Store) Create(user *User) error {
 			case "users_email_key":
 				return ErrEmailAlreadyExists
 			}
+			return err
 		default:
 			return err
 		}
@@ -60,7 +62,7 @@ func (s *Store) Create(user *User) error {
 	return nil
 }
 
-func (s *Store) UpdateUser(user *User) error {
+func (s *Store) UpdateUser(ctx context.Context, user *User) error {
 	query := `
 		UPDATE 
 			users
@@ -70,16 +72,17 @@ func (s *Store) UpdateUser(user *User) error {
 			password_hash 	= $3, 
 			last_login 		= $4, 
 			activated 		= $5, 
+			language 		= NULLIF($6, ''), 
 			version 		= version + 1
 		WHERE	
-			id = $6 
+			id = $7 
 		AND 
-			version = $7
+			version = $8
 		RETURNING
 			version
 	`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	args := []any{
@@ -88,6 +91,7 @@ func (s *Store) UpdateUser(user *User) error {
 		user.Password.hash,
 		user.LastLogin,
 		user.Activated,
+		user.Language,
 		user.ID,
 		user.Version,
 	}
@@ -103,6 +107,9 @@ func (s *Store) UpdateUser(user *User) error {
 			case "users_email_key":
 				return ErrEmailAlreadyExists
 			}
+			return err
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrEditConflict
 		default:
 			return err
 		}
@@ -111,41 +118,57 @@ func (s *Store) UpdateUser(user *User) error {
 	return nil
 }
 
-func (s *Store) DeleteUser(username string) error {
+// DeleteUser deletes the user at the given version. It returns
+// ErrEditConflict when the user exists at another version.
+func (s *Store) DeleteUser(ctx context.Context, username string, version int) error {
 	query := `
-		DELETE FROM 
-			users
-		WHERE 
-			username = $1
+		WITH deleted AS (
+			DELETE FROM 
+				users
+			WHERE 
+				username = $1
+			AND
+				version = $2
+			RETURNING
+				id
+		)
+		SELECT
+			(SELECT count(*) FROM deleted),
+			EXISTS (SELECT 1 FROM users WHERE username = $1)
 	`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	cmdTag, err := s.pool.Exec(ctx, query, username)
-	if err != nil {
+	var deleted int
+	var exists bool
+
+	if err := s.pool.QueryRow(ctx, query, username, version).Scan(&deleted, &exists); err != nil {
 		return err
 	}
 
-	if cmdTag.RowsAffected() == 0 {
+	switch {
+	case deleted > 0:
+		return nil
+	case exists:
+		return ErrEditConflict
+	default:
 		return ErrRecordNotFound
 	}
-
-	return nil
 }
 
-func (s *Store) GetUserByEmail(email string) (*User, error) {
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
 		SELECT 
 			id, username, email, password_hash, 
-			created_at, last_login, activated, version
+			created_at, last_login, activated, COALESCE(language, ''), roles, version
 		FROM
 			users
 		WHERE
 			email = $1
 	`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var user User
@@ -158,6 +181,8 @@ func (s *Store) GetUserByEmail(email string) (*User, error) {
 		&user.CreatedAt,
 		&user.LastLogin,
 		&user.Activated,
+		&user.Language,
+		&user.Roles,
 		&user.Version,
 	)
 
@@ -173,18 +198,18 @@ func (s *Store) GetUserByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
-func (s *Store) GetUserByUsername(username string) (*User, error) {
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*User, error) {
 	query := `
 		SELECT 
 			id, username, email, password_hash, 
-			created_at, last_login, activated, version
+			created_at, last_login, activated, COALESCE(language, ''), roles, version
 		FROM
 			users
 		WHERE
 			username = $1
 	`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var user User
@@ -197,6 +222,8 @@ func (s *Store) GetUserByUsername(username string) (*User, error) {
 		&user.CreatedAt,
 		&user.LastLogin,
 		&user.Activated,
+		&user.Language,
+		&user.Roles,
 		&user.Version,
 	)
 
@@ -212,11 +239,11 @@ func (s *Store) GetUserByUsername(username string) (*User, error) {
 	return &user, nil
 }
 
-func (s *Store) GetUsers() ([]*User, error) {
+func (s *Store) GetUsers(ctx context.Context) ([]*User, error) {
 	query := `
 		SELECT 
 			id, username, email, password_hash, 
-			created_at, last_login, activated, version
+			created_at, last_login, activated, COALESCE(language, ''), roles, version
 		FROM
 			users
 		ORDER BY 
@@ -225,7 +252,7 @@ func (s *Store) GetUsers() ([]*User, error) {
 			10	
 	`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	rows, err := s.pool.Query(ctx, query)
@@ -247,6 +274,8 @@ func (s *Store) GetUsers() ([]*User, error) {
 			&user.CreatedAt,
 			&user.LastLogin,
 			&user.Activated,
+			&user.Language,
+			&user.Roles,
 			&user.Version,
 		)
 
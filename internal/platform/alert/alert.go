@@ -0,0 +1,93 @@
+// Package alert delivers alerts raised by the service, typically from error
+// logs, to external sinks without blocking the caller.
+package alert
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Alert struct {
+	Fingerprint string         `json:"fingerprint"`
+	Service     string         `json:"service"`
+	Time        time.Time      `json:"time"`
+	Level       string         `json:"level"`
+	Message     string         `json:"message"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+
+	// Suppressed counts duplicates of this alert dropped since the previous
+	// one with the same fingerprint was delivered.
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Sink delivers a batch of alerts to one destination.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, alerts []Alert) error
+}
+
+// =============================================================================
+
+var volatileRX = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,}|\d+`)
+
+// Fingerprint identifies alerts that describe the same problem. It hashes the
+// message and the error attribute with IDs and numbers blanked out, so the
+// same failure for different requests collapses into one alert.
+func Fingerprint(msg string, attrs map[string]any) string {
+	h := sha256.New()
+
+	h.Write([]byte(volatileRX.ReplaceAllString(msg, "#")))
+
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		if key != "error" && key != "type" && key != "source" && key != "func" {
+			continue
+		}
+
+		fmt.Fprintf(h, "\x00%s=%s", key, volatileRX.ReplaceAllString(fmt.Sprint(attrs[key]), "#"))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Subject returns a one line summary suitable for an email subject.
+func Subject(alerts []Alert) string {
+	switch len(alerts) {
+	case 0:
+		return "no alerts"
+	case 1:
+		return fmt.Sprintf("[%s] %s: %s", alerts[0].Level, alerts[0].Service, alerts[0].Message)
+	default:
+		return fmt.Sprintf("[%s] %s: %d alerts", alerts[0].Level, alerts[0].Service, len(alerts))
+	}
+}
+
+// Text renders alerts as plain text for human readers.
+func Text(alerts []Alert) string {
+	var b strings.Builder
+
+	for i, a := range alerts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "%s %s %s\n", a.Time.UTC().Format(time.RFC3339), a.Level, a.Message)
+		fmt.Fprintf(&b, "  fingerprint: %s\n", a.Fingerprint)
+
+		if a.Suppressed > 0 {
+			fmt.Fprintf(&b, "  suppressed duplicates: %d\n", a.Suppressed)
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(a.Attributes)) {
+			fmt.Fprintf(&b, "  %s: %v\n", key, a.Attributes[key])
+		}
+	}
+
+	return b.String()
+}
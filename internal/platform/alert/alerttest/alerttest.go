@@ -0,0 +1,74 @@
+// Package alerttest provides a local webhook receiver for exercising alert
+// delivery without a real alerting service.
+package alerttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/agkmw/reddit-clone/internal/platform/alert"
+)
+
+// Receiver is an httptest server that records every batch posted to it by an
+// alert.Webhook.
+type Receiver struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	batches [][]alert.Alert
+	status  int
+}
+
+func NewReceiver() *Receiver {
+	rcv := Receiver{status: http.StatusNoContent}
+
+	rcv.Server = httptest.NewServer(http.HandlerFunc(rcv.serve))
+
+	return &rcv
+}
+
+func (rcv *Receiver) serve(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Alerts []alert.Alert `json:"alerts"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rcv.mu.Lock()
+	rcv.batches = append(rcv.batches, body.Alerts)
+	status := rcv.status
+	rcv.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+// SetStatus sets the status returned to the sender. It defaults to 204.
+func (rcv *Receiver) SetStatus(status int) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	rcv.status = status
+}
+
+// Batches returns a copy of every batch received so far.
+func (rcv *Receiver) Batches() [][]alert.Alert {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return append([][]alert.Alert(nil), rcv.batches...)
+}
+
+// Alerts returns every alert received so far, across batches.
+func (rcv *Receiver) Alerts() []alert.Alert {
+	var alerts []alert.Alert
+	for _, b := range rcv.Batches() {
+		alerts = append(alerts, b...)
+	}
+
+	return alerts
+}
@@ -0,0 +1,247 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type Config struct {
+	Sinks []Sink
+
+	// QueueSize bounds alerts waiting for delivery. Alerts raised while the
+	// queue is full are dropped. Defaults to 256.
+	QueueSize int
+
+	// FlushInterval is how long alerts are batched before delivery.
+	// Defaults to 10 seconds.
+	FlushInterval time.Duration
+
+	// DedupWindow suppresses alerts with a fingerprint already delivered
+	// within the window. Defaults to 10 minutes.
+	DedupWindow time.Duration
+
+	// PerMinute caps how many alerts are delivered per minute across all
+	// fingerprints. Defaults to 20.
+	PerMinute int
+
+	// SendTimeout bounds one delivery to one sink. Defaults to 10 seconds.
+	SendTimeout time.Duration
+
+	// ErrorFn reports delivery failures. It must not raise alerts itself.
+	ErrorFn func(ctx context.Context, sink string, err error)
+}
+
+// Dispatcher deduplicates, rate limits and batches alerts and delivers them
+// to every sink from a background goroutine started with Run.
+type Dispatcher struct {
+	cfg     Config
+	queue   chan Alert
+	limiter *rate.Limiter
+	dropped atomic.Uint64
+
+	mu   sync.Mutex
+	seen map[string]*seenAlert
+}
+
+type seenAlert struct {
+	sentAt     time.Time
+	suppressed int
+}
+
+func NewDispatcher(cfg Config) *Dispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 256
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	if cfg.DedupWindow <= 0 {
+		cfg.DedupWindow = 10 * time.Minute
+	}
+	if cfg.PerMinute <= 0 {
+		cfg.PerMinute = 20
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 10 * time.Second
+	}
+
+	return &Dispatcher{
+		cfg:     cfg,
+		queue:   make(chan Alert, cfg.QueueSize),
+		limiter: rate.NewLimiter(rate.Limit(float64(cfg.PerMinute)/60), cfg.PerMinute),
+		seen:    make(map[string]*seenAlert),
+	}
+}
+
+// Notify queues an alert without blocking. Duplicates within the dedup
+// window are counted and dropped.
+func (d *Dispatcher) Notify(a Alert) {
+	if d == nil || len(d.cfg.Sinks) == 0 {
+		return
+	}
+
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	if a.Fingerprint == "" {
+		a.Fingerprint = Fingerprint(a.Message, a.Attributes)
+	}
+
+	if !d.admit(&a) {
+		return
+	}
+
+	select {
+	case d.queue <- a:
+	default:
+		d.dropped.Add(1)
+		d.release(a)
+	}
+}
+
+// Dropped reports alerts lost to a full queue or the rate limit.
+func (d *Dispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Run delivers queued alerts until ctx is canceled, then flushes what is
+// left within SendTimeout.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []Alert
+
+	for {
+		select {
+		case a := <-d.queue:
+			batch = append(batch, a)
+
+		case <-ticker.C:
+			d.flush(ctx, batch)
+			batch = nil
+			d.prune(time.Now())
+
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case a := <-d.queue:
+					batch = append(batch, a)
+				default:
+					break drain
+				}
+			}
+
+			d.flush(context.WithoutCancel(ctx), batch)
+
+			return nil
+		}
+	}
+}
+
+// =============================================================================
+
+func (d *Dispatcher) admit(a *Alert) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.seen[a.Fingerprint]
+	if ok && a.Time.Sub(s.sentAt) < d.cfg.DedupWindow {
+		s.suppressed++
+		return false
+	}
+
+	if ok {
+		a.Suppressed = s.suppressed
+	}
+
+	d.seen[a.Fingerprint] = &seenAlert{sentAt: a.Time}
+
+	return true
+}
+
+// release forgets that a was admitted when it was not delivered after all,
+// so that the next alert with its fingerprint goes out. a and the duplicates
+// it suppressed are counted as suppressed on that alert.
+func (d *Dispatcher) release(a Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.seen[a.Fingerprint]
+	if !ok || !s.sentAt.Equal(a.Time) {
+		return
+	}
+
+	s.sentAt = time.Time{}
+	s.suppressed += a.Suppressed + 1
+}
+
+func (d *Dispatcher) prune(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for fp, s := range d.seen {
+		if now.Sub(s.sentAt) >= d.cfg.DedupWindow && s.suppressed == 0 {
+			delete(d.seen, fp)
+		}
+	}
+}
+
+func (d *Dispatcher) flush(ctx context.Context, batch []Alert) {
+	if len(batch) == 0 {
+		return
+	}
+
+	allowed := batch[:0]
+	for _, a := range batch {
+		if d.limiter.Allow() {
+			allowed = append(allowed, a)
+			continue
+		}
+
+		d.release(a)
+	}
+
+	if over := len(batch) - len(allowed); over > 0 {
+		d.dropped.Add(uint64(over))
+		d.reportError(ctx, "dispatcher", fmt.Errorf("dropped %d alerts over the limit of %d per minute", over, d.cfg.PerMinute))
+	}
+
+	if len(allowed) == 0 {
+		return
+	}
+
+	var delivered bool
+
+	for _, sink := range d.cfg.Sinks {
+		sendCtx, cancel := context.WithTimeout(ctx, d.cfg.SendTimeout)
+		err := sink.Send(sendCtx, allowed)
+		cancel()
+
+		if err != nil {
+			d.reportError(ctx, sink.Name(), err)
+			continue
+		}
+
+		delivered = true
+	}
+
+	// Alerts no sink took must not hold back their next occurrence.
+	if !delivered {
+		for _, a := range allowed {
+			d.release(a)
+		}
+	}
+}
+
+func (d *Dispatcher) reportError(ctx context.Context, sink string, err error) {
+	if d.cfg.ErrorFn != nil {
+		d.cfg.ErrorFn(ctx, sink, err)
+	}
+}
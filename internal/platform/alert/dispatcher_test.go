@@ -0,0 +1,192 @@
+package alert_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/alert"
+	"github.com/agkmw/reddit-clone/internal/platform/alert/alerttest"
+)
+
+// deliver runs d until everything queued so far has been flushed.
+func deliver(t *testing.T, d *alert.Dispatcher) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+type errorLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (l *errorLog) record(ctx context.Context, sink string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errs = append(l.errs, err)
+}
+
+func (l *errorLog) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.errs)
+}
+
+func TestDispatcherDeduplicates(t *testing.T) {
+	rcv := alerttest.NewReceiver()
+	defer rcv.Close()
+
+	d := alert.NewDispatcher(alert.Config{
+		Sinks:         []alert.Sink{alert.NewWebhook(rcv.URL)},
+		FlushInterval: time.Hour,
+		DedupWindow:   time.Minute,
+	})
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		at         time.Duration
+		delivered  bool
+		suppressed int
+	}{
+		{at: 0, delivered: true},
+		{at: time.Second},
+		{at: 59 * time.Second},
+		{at: time.Minute, delivered: true, suppressed: 2},
+		{at: time.Minute + time.Second},
+	}
+
+	for _, tt := range tests {
+		before := len(rcv.Alerts())
+
+		d.Notify(alert.Alert{Message: "db down", Time: t0.Add(tt.at)})
+		deliver(t, d)
+
+		got := rcv.Alerts()
+		if delivered := len(got) > before; delivered != tt.delivered {
+			t.Fatalf("at %v: delivered %v, want %v", tt.at, delivered, tt.delivered)
+		}
+
+		if tt.delivered && got[len(got)-1].Suppressed != tt.suppressed {
+			t.Errorf("at %v: suppressed %d, want %d", tt.at, got[len(got)-1].Suppressed, tt.suppressed)
+		}
+	}
+}
+
+func TestDispatcherRateLimitDoesNotSuppress(t *testing.T) {
+	rcv := alerttest.NewReceiver()
+	defer rcv.Close()
+
+	var errs errorLog
+
+	d := alert.NewDispatcher(alert.Config{
+		Sinks:         []alert.Sink{alert.NewWebhook(rcv.URL)},
+		FlushInterval: time.Hour,
+		DedupWindow:   time.Hour,
+		PerMinute:     1,
+		ErrorFn:       errs.record,
+	})
+
+	now := time.Now()
+
+	d.Notify(alert.Alert{Message: "first", Time: now})
+	d.Notify(alert.Alert{Message: "second", Time: now})
+	deliver(t, d)
+
+	if got := rcv.Alerts(); len(got) != 1 || got[0].Message != "first" {
+		t.Fatalf("got %+v, want only the first alert", got)
+	}
+	if d.Dropped() != 1 || errs.len() != 1 {
+		t.Fatalf("dropped %d with %d errors, want 1 and 1", d.Dropped(), errs.len())
+	}
+
+	// The limiter is still exhausted, so the retry is dropped again rather
+	// than being suppressed as a duplicate of an alert never delivered.
+	d.Notify(alert.Alert{Message: "second", Time: now.Add(time.Second)})
+	deliver(t, d)
+
+	if d.Dropped() != 2 {
+		t.Fatalf("dropped %d, want 2: the retry was suppressed", d.Dropped())
+	}
+}
+
+func TestDispatcherFailedDeliveryDoesNotSuppress(t *testing.T) {
+	rcv := alerttest.NewReceiver()
+	defer rcv.Close()
+
+	var errs errorLog
+
+	d := alert.NewDispatcher(alert.Config{
+		Sinks:         []alert.Sink{alert.NewWebhook(rcv.URL)},
+		FlushInterval: time.Hour,
+		DedupWindow:   time.Hour,
+		ErrorFn:       errs.record,
+	})
+
+	now := time.Now()
+
+	rcv.SetStatus(http.StatusServiceUnavailable)
+
+	d.Notify(alert.Alert{Message: "db down", Time: now})
+	deliver(t, d)
+
+	if errs.len() != 1 {
+		t.Fatalf("got %d errors, want 1", errs.len())
+	}
+
+	rcv.SetStatus(http.StatusNoContent)
+
+	d.Notify(alert.Alert{Message: "db down", Time: now.Add(time.Second)})
+	deliver(t, d)
+
+	batches := rcv.Batches()
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want the failed one and the retry", len(batches))
+	}
+
+	if got := batches[1][0].Suppressed; got != 1 {
+		t.Errorf("retry suppressed %d, want 1 for the failed delivery", got)
+	}
+}
+
+func TestDispatcherBatchesUntilFlush(t *testing.T) {
+	rcv := alerttest.NewReceiver()
+	defer rcv.Close()
+
+	d := alert.NewDispatcher(alert.Config{
+		Sinks:         []alert.Sink{alert.NewWebhook(rcv.URL)},
+		FlushInterval: 20 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- d.Run(ctx) }()
+
+	d.Notify(alert.Alert{Message: "one"})
+	d.Notify(alert.Alert{Message: "two"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rcv.Alerts()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if got := rcv.Alerts(); len(got) != 2 {
+		t.Fatalf("got %d alerts, want 2", len(got))
+	}
+}
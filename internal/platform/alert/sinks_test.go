@@ -0,0 +1,71 @@
+package alert_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/alert"
+	"github.com/agkmw/reddit-clone/internal/platform/alert/alerttest"
+)
+
+func TestWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "no content", status: http.StatusNoContent},
+		{name: "ok", status: http.StatusOK},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+		{name: "not modified", status: http.StatusNotModified, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rcv := alerttest.NewReceiver()
+			defer rcv.Close()
+
+			rcv.SetStatus(tt.status)
+
+			alerts := []alert.Alert{
+				{
+					Fingerprint: "abc",
+					Service:     "api",
+					Time:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					Level:       "ERROR",
+					Message:     "db down",
+					Attributes:  map[string]any{"error": "connection refused"},
+					Suppressed:  3,
+				},
+				{Fingerprint: "def", Message: "disk full"},
+			}
+
+			err := alert.NewWebhook(rcv.URL).Send(context.Background(), alerts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+
+			batches := rcv.Batches()
+			if len(batches) != 1 || len(batches[0]) != 2 {
+				t.Fatalf("got batches %+v, want one batch of 2", batches)
+			}
+
+			got := batches[0][0]
+			if got.Fingerprint != "abc" || got.Message != "db down" || got.Suppressed != 3 ||
+				!got.Time.Equal(alerts[0].Time) || got.Attributes["error"] != "connection refused" {
+				t.Errorf("got %+v, want %+v", got, alerts[0])
+			}
+		})
+	}
+}
+
+func TestWebhookUnreachable(t *testing.T) {
+	rcv := alerttest.NewReceiver()
+	rcv.Close()
+
+	if err := alert.NewWebhook(rcv.URL).Send(context.Background(), []alert.Alert{{Message: "x"}}); err == nil {
+		t.Fatal("expected an error for a closed receiver")
+	}
+}
@@ -0,0 +1,43 @@
+// Package auth carries the identity authentication established for a request,
+// so that everything deciding per user, such as feature flags and rate
+// limits, reads it from the same place.
+package auth
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Identity is who made a request. The zero value is an anonymous request.
+type Identity struct {
+	UserID string
+	Roles  []string
+
+	// Since is when the user's account was created.
+	Since time.Time
+}
+
+func (id Identity) Anonymous() bool {
+	return id.UserID == ""
+}
+
+func (id Identity) HasRole(role string) bool {
+	return slices.Contains(id.Roles, role)
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const identityKey ctxKey = 1
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey, id)
+}
+
+// GetIdentity returns the identity stored in ctx, or the anonymous identity.
+func GetIdentity(ctx context.Context) Identity {
+	id, _ := ctx.Value(identityKey).(Identity)
+	return id
+}
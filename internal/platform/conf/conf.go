@@ -0,0 +1,278 @@
+// Package conf layers configuration from defaults, a JSON, YAML or TOML file,
+// environment variables and command line flags onto a flag.FlagSet.
+//
+// Precedence, lowest to highest: flag defaults, config file, environment,
+// command line. Every flag "db-max-conns" maps to the file key
+// "db-max-conns" and the environment variable PREFIX_DB_MAX_CONNS. Setting
+// PREFIX_DB_DSN_FILE reads the value from that file instead, for secrets
+// mounted by an orchestrator.
+package conf
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrHelp is returned when -h or -help was requested. Usage has already been
+// printed.
+var ErrHelp = flag.ErrHelp
+
+// Source names where the effective value of a setting came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+type Config struct {
+	// Prefix for environment variables, e.g. "NEXUS".
+	Prefix string
+
+	// FileFlag names the flag holding the config file path. The file is
+	// skipped when the flag is empty or not defined.
+	FileFlag string
+
+	// Required lists flags that must end up with a non-empty value.
+	Required []string
+
+	Getenv   func(string) string
+	ReadFile func(string) ([]byte, error)
+}
+
+// Sources records where each flag's value came from.
+type Sources map[string]Source
+
+// Parse parses args into fs and then fills every flag not given on the
+// command line from the config file, then from the environment. Required
+// settings are not checked; call Validate once the caller is done with fs.
+func Parse(fs *flag.FlagSet, args []string, cfg Config) (Sources, error) {
+	if cfg.Getenv == nil {
+		cfg.Getenv = os.Getenv
+	}
+	if cfg.ReadFile == nil {
+		cfg.ReadFile = os.ReadFile
+	}
+
+	fs.Usage = func() { usage(fs, cfg) }
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	sources := make(Sources)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		sources[f.Name] = SourceDefault
+	})
+	fs.Visit(func(f *flag.Flag) {
+		sources[f.Name] = SourceFlag
+	})
+
+	// The config file path itself may come from the environment.
+	if cfg.FileFlag != "" && sources[cfg.FileFlag] == SourceDefault {
+		if err := setFromEnv(fs, fs.Lookup(cfg.FileFlag), cfg, sources); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyFile(fs, cfg, sources); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if sources[f.Name] == SourceFlag || f.Name == cfg.FileFlag {
+			return
+		}
+
+		if err := setFromEnv(fs, f, cfg, sources); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// EnvName returns the environment variable read for the named flag.
+func EnvName(prefix, name string) string {
+	env := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if prefix == "" {
+		return env
+	}
+
+	return prefix + "_" + env
+}
+
+// Validate reports every required setting that is still empty.
+func Validate(fs *flag.FlagSet, cfg Config) error {
+	var errs []error
+
+	for _, name := range cfg.Required {
+		f := fs.Lookup(name)
+		if f == nil {
+			errs = append(errs, fmt.Errorf("required setting %q is not defined", name))
+			continue
+		}
+
+		if f.Value.String() == "" {
+			errs = append(errs, fmt.Errorf("required setting %q is missing: set -%s or %s", name, name, EnvName(cfg.Prefix, name)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// =============================================================================
+
+func applyFile(fs *flag.FlagSet, cfg Config, sources Sources) error {
+	if cfg.FileFlag == "" {
+		return nil
+	}
+
+	f := fs.Lookup(cfg.FileFlag)
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+
+	path := f.Value.String()
+
+	data, err := cfg.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	values, err := decodeFile(path, data)
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if name == cfg.FileFlag {
+			continue
+		}
+
+		if fs.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("config file %s: unknown setting %q", path, name))
+			continue
+		}
+
+		if sources[name] == SourceFlag {
+			continue
+		}
+
+		value, err := fileValue(values[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, name, err))
+			continue
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, name, err))
+			continue
+		}
+
+		sources[name] = SourceFile
+	}
+
+	return errors.Join(errs...)
+}
+
+func fileValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+func setFromEnv(fs *flag.FlagSet, f *flag.Flag, cfg Config, sources Sources) error {
+	if f == nil {
+		return nil
+	}
+
+	env := EnvName(cfg.Prefix, f.Name)
+
+	value, ok := cfg.Getenv(env), false
+	if value != "" {
+		ok = true
+	}
+
+	if path := cfg.Getenv(env + "_FILE"); path != "" {
+		if ok {
+			return fmt.Errorf("%s and %s_FILE are both set", env, env)
+		}
+
+		data, err := cfg.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %w", env, err)
+		}
+
+		value, ok = strings.TrimRight(string(data), "\r\n"), true
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if err := fs.Set(f.Name, value); err != nil {
+		return fmt.Errorf("%s: %w", env, err)
+	}
+
+	sources[f.Name] = SourceEnv
+
+	return nil
+}
+
+func usage(fs *flag.FlagSet, cfg Config) {
+	w := fs.Output()
+
+	fmt.Fprintf(w, "Usage of %s:\n", fs.Name())
+
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+
+		fmt.Fprintf(w, "  -%s", f.Name)
+		if name != "" {
+			fmt.Fprintf(w, " %s", name)
+		}
+		fmt.Fprintf(w, "\n    \t%s", strings.ReplaceAll(usage, "\n", "\n    \t"))
+
+		switch {
+		case f.DefValue == "" || f.DefValue == "0" || f.DefValue == "false":
+		case name == "string":
+			fmt.Fprintf(w, " (default %q)", f.DefValue)
+		default:
+			fmt.Fprintf(w, " (default %s)", f.DefValue)
+		}
+		if slices.Contains(cfg.Required, f.Name) {
+			fmt.Fprint(w, " (required)")
+		}
+
+		fmt.Fprintf(w, "\n    \tenv: %s", EnvName(cfg.Prefix, f.Name))
+		if f.Name != cfg.FileFlag {
+			fmt.Fprintf(w, ", %s_FILE", EnvName(cfg.Prefix, f.Name))
+		}
+		fmt.Fprintln(w)
+	})
+
+	fmt.Fprintln(w, "\nPrecedence: command line > environment > config file > default.")
+}
@@ -0,0 +1,260 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// format is a line based config syntax. It decides how quoted strings are
+// read.
+type format int
+
+const (
+	formatYAML format = iota
+	formatTOML
+)
+
+// decodeFile parses a config file by its extension. Settings are flat, so
+// YAML and TOML files are read as a single mapping or table of scalars;
+// nesting, sequences, arrays and multi-line strings are rejected. Files
+// without an extension are read as JSON.
+func decodeFile(path string, data []byte) (map[string]any, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", "":
+		var values map[string]any
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		return values, nil
+
+	case ".yaml", ".yml":
+		return parseLines(data, parseYAMLLine)
+
+	case ".toml":
+		return parseLines(data, parseTOMLLine)
+
+	default:
+		return nil, fmt.Errorf("unsupported format %q, use .json, .yaml, .yml or .toml", ext)
+	}
+}
+
+// parseLines parses data line by line. parse returns an empty key for lines
+// that hold no setting.
+func parseLines(data []byte, parse func(line string) (string, string, error)) (map[string]any, error) {
+	values := make(map[string]any)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		key, value, err := parse(strings.TrimRight(line, "\r"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if key == "" {
+			continue
+		}
+
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", i+1, key)
+		}
+
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+func parseYAMLLine(line string) (string, string, error) {
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case trimmed == "" || trimmed[0] == '#' || trimmed == "---" || trimmed == "...":
+		return "", "", nil
+	case line[0] == ' ' || line[0] == '\t':
+		return "", "", errors.New("nested values are not supported")
+	case trimmed[0] == '-':
+		return "", "", errors.New("sequences are not supported")
+	}
+
+	key, rest, err := cutKey(formatYAML, line, ':')
+	if err != nil {
+		return "", "", err
+	}
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", "", errors.New(`expected "key: value"`)
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	if rest == "" || rest[0] == '#' {
+		return "", "", fmt.Errorf("%s: missing value; nested values are not supported", key)
+	}
+
+	switch rest[0] {
+	case '"', '\'':
+		value, err := quoted(formatYAML, rest)
+		return key, value, err
+
+	case '[', '{', '|', '>', '&', '*', '!':
+		return "", "", fmt.Errorf("%s: only plain and quoted scalars are supported", key)
+	}
+
+	value := rest
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+
+	switch value {
+	case "~", "null", "Null", "NULL":
+		return "", "", fmt.Errorf("%s: null values are not supported", key)
+	}
+
+	return key, value, nil
+}
+
+func parseTOMLLine(line string) (string, string, error) {
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case trimmed == "" || trimmed[0] == '#':
+		return "", "", nil
+	case trimmed[0] == '[':
+		return "", "", errors.New("tables are not supported")
+	}
+
+	key, rest, err := cutKey(formatTOML, trimmed, '=')
+	if err != nil {
+		return "", "", err
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	if rest == "" {
+		return "", "", fmt.Errorf("%s: missing value", key)
+	}
+
+	switch rest[0] {
+	case '"', '\'':
+		if strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''") {
+			return "", "", fmt.Errorf("%s: multi-line strings are not supported", key)
+		}
+		value, err := quoted(formatTOML, rest)
+		return key, value, err
+
+	case '[', '{':
+		return "", "", fmt.Errorf("%s: arrays and inline tables are not supported", key)
+	}
+
+	value := rest
+	if i := strings.IndexByte(value, '#'); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+
+	if value == "true" || value == "false" {
+		return key, value, nil
+	}
+
+	number := strings.ReplaceAll(value, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return "", "", fmt.Errorf("%s: unsupported value %q; quote strings and durations", key, value)
+	}
+
+	return key, number, nil
+}
+
+// cutKey splits a line at sep into a bare or quoted key and the rest.
+func cutKey(f format, line string, sep byte) (string, string, error) {
+	line = strings.TrimLeft(line, " \t")
+
+	if line[0] == '"' || line[0] == '\'' {
+		end := closingQuote(f, line)
+		if end < 0 {
+			return "", "", errors.New("unterminated quoted key")
+		}
+
+		key, err := unquote(f, line[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+
+		rest := strings.TrimLeft(line[end+1:], " \t")
+		if rest == "" || rest[0] != sep {
+			return "", "", fmt.Errorf("expected %q after key %q", sep, key)
+		}
+
+		return key, rest[1:], nil
+	}
+
+	i := strings.IndexByte(line, sep)
+	if i < 0 {
+		return "", "", fmt.Errorf("expected %q", sep)
+	}
+
+	key := strings.TrimSpace(line[:i])
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return "", "", fmt.Errorf("invalid key %q; dotted and nested keys are not supported", key)
+		}
+	}
+	if key == "" {
+		return "", "", errors.New("missing key")
+	}
+
+	return key, line[i+1:], nil
+}
+
+// quoted unquotes the string that s starts with and rejects anything but a
+// comment after it.
+func quoted(f format, s string) (string, error) {
+	end := closingQuote(f, s)
+	if end < 0 {
+		return "", errors.New("unterminated string")
+	}
+
+	if rest := strings.TrimSpace(s[end+1:]); rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected %q after string", rest)
+	}
+
+	return unquote(f, s[:end+1])
+}
+
+// closingQuote returns the index of the quote closing the string s starts
+// with, or -1. Double quoted strings use backslash escapes; single quoted
+// ones are literal, except that YAML doubles a single quote inside them.
+// TOML has no such escape, so there the first quote after the opening one
+// closes the string.
+func closingQuote(f format, s string) int {
+	q := s[0]
+
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && f == formatYAML && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unquote(f format, s string) (string, error) {
+	if s[0] == '\'' {
+		if f == formatYAML {
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+		}
+		return s[1 : len(s)-1], nil
+	}
+
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+
+	return v, nil
+}
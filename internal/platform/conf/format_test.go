@@ -0,0 +1,92 @@
+package conf
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestDecodeFile(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data string
+		want map[string]any
+		err  string
+	}{
+		{
+			name: "json",
+			path: "api.json",
+			data: `{"port": 4000, "db-dsn": "postgres://x"}`,
+			want: map[string]any{"port": 4000.0, "db-dsn": "postgres://x"},
+		},
+		{
+			name: "yaml",
+			path: "api.yaml",
+			data: "---\n# comment\nport: 4000\ndb-dsn: \"postgres://x?a=b#c\"\nlog-level: debug # inline\n'cors-origins': 'it''s'\nlimiter-enabled: true\n",
+			want: map[string]any{
+				"port":            "4000",
+				"db-dsn":          "postgres://x?a=b#c",
+				"log-level":       "debug",
+				"cors-origins":    "it's",
+				"limiter-enabled": "true",
+			},
+		},
+		{
+			name: "yml",
+			path: "api.YML",
+			data: "shutdown-drain: 5s\r\n",
+			want: map[string]any{"shutdown-drain": "5s"},
+		},
+		{
+			name: "toml",
+			path: "api.toml",
+			data: "# comment\nport = 4_000\ndb-dsn = \"postgres://x\\u0041\" # inline\nlog-level = 'debug'\n'cors-origins' = 'C:\\it\\s'\nlimiter-enabled = false\nlimiter-rps = 2.5\n",
+			want: map[string]any{
+				"port":            "4000",
+				"db-dsn":          "postgres://xA",
+				"log-level":       "debug",
+				"cors-origins":    `C:\it\s`,
+				"limiter-enabled": "false",
+				"limiter-rps":     "2.5",
+			},
+		},
+		{name: "unknown extension", path: "api.ini", data: "port=1", err: `unsupported format ".ini"`},
+		{name: "yaml nested", path: "a.yaml", data: "db:\n  dsn: x\n", err: "line 1: db: missing value"},
+		{name: "yaml indented", path: "a.yaml", data: "  dsn: x\n", err: "line 1: nested values"},
+		{name: "yaml sequence", path: "a.yaml", data: "- a\n", err: "line 1: sequences"},
+		{name: "yaml flow", path: "a.yaml", data: "a: [1, 2]\n", err: "only plain and quoted scalars"},
+		{name: "yaml null", path: "a.yaml", data: "a: ~\n", err: "null values"},
+		{name: "yaml duplicate", path: "a.yaml", data: "a: 1\na: 2\n", err: `line 2: duplicate key "a"`},
+		{name: "yaml unterminated", path: "a.yaml", data: "a: \"x\n", err: "unterminated string"},
+		{name: "toml table", path: "a.toml", data: "[db]\n", err: "tables are not supported"},
+		{name: "toml dotted", path: "a.toml", data: "db.dsn = \"x\"\n", err: "dotted and nested keys"},
+		{name: "toml array", path: "a.toml", data: "a = [1]\n", err: "arrays and inline tables"},
+		{name: "toml bare string", path: "a.toml", data: "a = 5s\n", err: "quote strings and durations"},
+		{name: "toml multi-line", path: "a.toml", data: "a = \"\"\"x\n", err: "multi-line strings"},
+		{name: "toml doubled quote", path: "a.toml", data: "a = 'it''s'\n", err: "after string"},
+		{name: "toml doubled quote in key", path: "a.toml", data: "'it''s' = 1\n", err: "after key"},
+		{name: "toml trailing", path: "a.toml", data: "a = \"x\" y\n", err: "after string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeFile(tt.path, []byte(tt.data))
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
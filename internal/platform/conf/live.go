@@ -0,0 +1,65 @@
+package conf
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Live holds settings that can change while the process runs. Readers load
+// the current value without locking; an update is validated first, swapped in
+// atomically and then announced to subscribers in registration order.
+type Live[T any] struct {
+	value    atomic.Pointer[T]
+	validate func(T) error
+
+	mu   sync.Mutex
+	subs []func(T)
+}
+
+func NewLive[T any](initial T, validate func(T) error) (*Live[T], error) {
+	if validate != nil {
+		if err := validate(initial); err != nil {
+			return nil, err
+		}
+	}
+
+	l := Live[T]{
+		validate: validate,
+	}
+	l.value.Store(&initial)
+
+	return &l, nil
+}
+
+func (l *Live[T]) Load() T {
+	return *l.value.Load()
+}
+
+// Subscribe registers fn to be called with every value applied by Update.
+func (l *Live[T]) Subscribe(fn func(T)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.subs = append(l.subs, fn)
+}
+
+// Update validates next and, if it is valid, makes it the current value. An
+// invalid value leaves the current one in place.
+func (l *Live[T]) Update(next T) error {
+	if l.validate != nil {
+		if err := l.validate(next); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.value.Store(&next)
+
+	for _, fn := range l.subs {
+		fn(next)
+	}
+
+	return nil
+}
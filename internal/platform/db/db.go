@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,6 +20,8 @@ type Config struct {
 	MaxConnLifeTime time.Duration
 
 	HealthCheckPeriod time.Duration
+
+	Tracer pgx.QueryTracer
 }
 
 func Open(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
@@ -33,6 +36,7 @@ func Open(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
 	pgxCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
 	pgxCfg.MaxConnLifetime = cfg.MaxConnLifeTime
 	pgxCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	pgxCfg.ConnConfig.Tracer = cfg.Tracer
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"github.com/agkmw/reddit-clone/internal/platform/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RegisterMetrics exposes pgxpool.Stat as gauges and counters that are read
+// on every scrape.
+func RegisterMetrics(reg *metrics.Registry, pool *pgxpool.Pool) {
+	gauge := func(name, help string, fn func(s *pgxpool.Stat) float64) {
+		reg.NewGaugeFunc(name, help, func() float64 { return fn(pool.Stat()) })
+	}
+
+	counter := func(name, help string, fn func(s *pgxpool.Stat) float64) {
+		reg.NewCounterFunc(name, help, func() float64 { return fn(pool.Stat()) })
+	}
+
+	gauge("db_pool_acquired_conns", "Number of currently acquired connections in the pool.", func(s *pgxpool.Stat) float64 {
+		return float64(s.AcquiredConns())
+	})
+	gauge("db_pool_idle_conns", "Number of currently idle connections in the pool.", func(s *pgxpool.Stat) float64 {
+		return float64(s.IdleConns())
+	})
+	gauge("db_pool_constructing_conns", "Number of connections with construction in progress.", func(s *pgxpool.Stat) float64 {
+		return float64(s.ConstructingConns())
+	})
+	gauge("db_pool_total_conns", "Total number of resources currently in the pool.", func(s *pgxpool.Stat) float64 {
+		return float64(s.TotalConns())
+	})
+	gauge("db_pool_max_conns", "Maximum size of the pool.", func(s *pgxpool.Stat) float64 {
+		return float64(s.MaxConns())
+	})
+
+	counter("db_pool_acquires_total", "Cumulative count of successful acquires from the pool.", func(s *pgxpool.Stat) float64 {
+		return float64(s.AcquireCount())
+	})
+	counter("db_pool_acquire_duration_seconds_total", "Total time spent waiting for successful acquires.", func(s *pgxpool.Stat) float64 {
+		return s.AcquireDuration().Seconds()
+	})
+	counter("db_pool_empty_acquires_total", "Cumulative count of acquires that waited for a connection.", func(s *pgxpool.Stat) float64 {
+		return float64(s.EmptyAcquireCount())
+	})
+	counter("db_pool_canceled_acquires_total", "Cumulative count of acquires canceled by a context.", func(s *pgxpool.Stat) float64 {
+		return float64(s.CanceledAcquireCount())
+	})
+	counter("db_pool_new_conns_total", "Cumulative count of new connections opened.", func(s *pgxpool.Stat) float64 {
+		return float64(s.NewConnsCount())
+	})
+	counter("db_pool_max_lifetime_destroys_total", "Cumulative count of connections destroyed for exceeding MaxConnLifetime.", func(s *pgxpool.Stat) float64 {
+		return float64(s.MaxLifetimeDestroyCount())
+	})
+	counter("db_pool_max_idle_destroys_total", "Cumulative count of connections destroyed for exceeding MaxConnIdleTime.", func(s *pgxpool.Stat) float64 {
+		return float64(s.MaxIdleDestroyCount())
+	})
+}
@@ -1,22 +1,29 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+)
 
 var ErrNilCause = errors.New("nil error cause")
 
 var (
-	Aborted            = ErrorType{"aborted"}
-	AlreadyExists      = ErrorType{"already_exists"}
-	EditConflict       = ErrorType{"edit_conflict"}
-	FailedPrecondition = ErrorType{"failed_precondition"}
-	FailedValidation   = ErrorType{"failed_validation"}
-	NotFound           = ErrorType{"not_found"}
-	Internal           = ErrorType{"internal"}
-	InvalidArgument    = ErrorType{"invalid_argument"}
-	PermissionDenied   = ErrorType{"permission_denied"}
-	TooManyRequests    = ErrorType{"too_many_requests"}
-	Unauthenticated    = ErrorType{"unauthenticated"}
-	Unknown            = ErrorType{"unknown"}
+	Aborted              = ErrorType{"aborted"}
+	AlreadyExists        = ErrorType{"already_exists"}
+	EditConflict         = ErrorType{"edit_conflict"}
+	FailedPrecondition   = ErrorType{"failed_precondition"}
+	FailedValidation     = ErrorType{"failed_validation"}
+	NotFound             = ErrorType{"not_found"}
+	Internal             = ErrorType{"internal"}
+	InvalidArgument      = ErrorType{"invalid_argument"}
+	PermissionDenied     = ErrorType{"permission_denied"}
+	PreconditionRequired = ErrorType{"precondition_required"}
+	RequestTooLarge      = ErrorType{"request_too_large"}
+	TooManyRequests      = ErrorType{"too_many_requests"}
+	Unauthenticated      = ErrorType{"unauthenticated"}
+	Unknown              = ErrorType{"unknown"}
+	UnsupportedMediaType = ErrorType{"unsupported_media_type"}
 )
 
 type ErrorType struct {
@@ -30,3 +37,17 @@ func (e ErrorType) String() string {
 func (e ErrorType) Equal(e2 ErrorType) bool {
 	return e.t == e2.t
 }
+
+// =============================================================================
+
+// DefaultMessage is the generic message for an error type, kept in the i18n
+// catalog under "error.<type>". Types without one get the message for
+// Unknown.
+func DefaultMessage(t ErrorType) i18n.Message {
+	key := "error." + t.String()
+	if !i18n.Default().Has(key) {
+		key = "error." + Unknown.String()
+	}
+
+	return i18n.Message{Key: key}
+}
@@ -3,20 +3,54 @@ package errs
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"runtime"
+
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
 )
 
 type ErrorInfo map[string]any
 
 type Error struct {
-	typ   ErrorType
-	cause error
-	data  ErrorInfo
-	file  string
-	fn    string
+	typ    ErrorType
+	msg    i18n.Message
+	cause  error
+	data   ErrorInfo
+	fields ErrorInfo
+	file   string
+	fn     string
 }
 
 func New(t ErrorType, cause error, data ErrorInfo) *Error {
+	return newError(t, cause, i18n.Message{}, data, nil)
+}
+
+// NewClientError carries msg to the client, rendered in the language the
+// request asked for.
+func NewClientError(t ErrorType, cause error, msg i18n.Message) *Error {
+	return newError(t, cause, msg, nil, nil)
+}
+
+func NewServerError(t ErrorType, cause error) *Error {
+	return newError(t, cause, i18n.Message{}, nil, nil)
+}
+
+func Wrap(err error, op string, fields ErrorInfo) *Error {
+	if err == nil {
+		err = ErrNilCause
+	}
+
+	cause := fmt.Errorf("%s: %w", op, err)
+
+	inner, ok := Get(err)
+	if !ok {
+		return newError(Unknown, cause, i18n.Message{}, nil, fields)
+	}
+
+	return newError(inner.typ, cause, inner.msg, inner.data, mergeFields(inner.fields, fields))
+}
+
+func newError(t ErrorType, cause error, msg i18n.Message, data, fields ErrorInfo) *Error {
 	if t.t == "" {
 		t = Unknown
 	}
@@ -25,17 +59,34 @@ func New(t ErrorType, cause error, data ErrorInfo) *Error {
 		cause = ErrNilCause
 	}
 
+	if t == Unknown {
+		if pgType, pgFields, ok := fromPg(cause); ok {
+			t = pgType
+			fields = mergeFields(pgFields, fields)
+		}
+	}
+
 	pc, file, line, _ := runtime.Caller(2)
 
 	return &Error{
-		typ:   t,
-		cause: cause,
-		data:  data,
-		file:  file,
-		fn:    fmt.Sprintf("%s:%d", runtime.FuncForPC(pc).Name(), line),
+		typ:    t,
+		msg:    msg,
+		cause:  cause,
+		data:   data,
+		fields: fields,
+		file:   file,
+		fn:     fmt.Sprintf("%s:%d", runtime.FuncForPC(pc).Name(), line),
 	}
 }
 
+func mergeFields(base, fields ErrorInfo) ErrorInfo {
+	merged := make(ErrorInfo, len(base)+len(fields))
+	maps.Copy(merged, base)
+	maps.Copy(merged, fields)
+
+	return merged
+}
+
 func (e *Error) Error() string {
 	return fmt.Sprintf(
 		"[%s] %s:%s: %v",
@@ -51,10 +102,34 @@ func (e *Error) Type() ErrorType {
 	return e.typ
 }
 
+// Message returns the client-safe message for the error. Server errors never
+// expose anything but the generic message for their type.
+func (e *Error) Message() string {
+	if msg, ok := e.ClientMessage(); ok {
+		return msg.String()
+	}
+
+	return DefaultMessage(e.typ).String()
+}
+
+// ClientMessage reports the message given by the caller, if any, that is safe
+// to show to clients.
+func (e *Error) ClientMessage() (i18n.Message, bool) {
+	if (e.msg.Key == "" && e.msg.Fallback == "") || e.typ == Internal || e.typ == Unknown {
+		return i18n.Message{}, false
+	}
+
+	return e.msg, true
+}
+
 func (e *Error) Data() ErrorInfo {
 	return e.data
 }
 
+func (e *Error) Fields() ErrorInfo {
+	return e.fields
+}
+
 func (e *Error) Location() (file, fn string) {
 	return e.file, e.fn
 }
@@ -0,0 +1,190 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDefaultMessageFromCatalog(t *testing.T) {
+	types := []errs.ErrorType{
+		errs.Aborted,
+		errs.AlreadyExists,
+		errs.EditConflict,
+		errs.FailedPrecondition,
+		errs.FailedValidation,
+		errs.NotFound,
+		errs.Internal,
+		errs.InvalidArgument,
+		errs.PermissionDenied,
+		errs.PreconditionRequired,
+		errs.RequestTooLarge,
+		errs.TooManyRequests,
+		errs.Unauthenticated,
+		errs.Unknown,
+		errs.UnsupportedMediaType,
+	}
+
+	spanish := i18n.Default().Localizer(i18n.Default().Match("es"))
+
+	for _, typ := range types {
+		t.Run(typ.String(), func(t *testing.T) {
+			msg := errs.DefaultMessage(typ)
+
+			if msg.Key != "error."+typ.String() {
+				t.Fatalf("key = %q, want %q", msg.Key, "error."+typ.String())
+			}
+			if !i18n.Default().Has(msg.Key) {
+				t.Fatalf("catalog has no %q", msg.Key)
+			}
+
+			en := msg.String()
+			if es := spanish.Localize(msg); es == en || es == msg.Key {
+				t.Errorf("no Spanish text for %q: %q", msg.Key, es)
+			}
+		})
+	}
+
+	if got := errs.DefaultMessage(errs.ErrorType{}); got.Key != "error.unknown" {
+		t.Errorf("type without a message: key = %q, want error.unknown", got.Key)
+	}
+}
+
+func TestCauseNotExposed(t *testing.T) {
+	cause := errors.New("dial tcp 10.0.0.7:5432: password authentication failed for user admin")
+
+	tests := []struct {
+		name     string
+		err      *errs.Error
+		wantType errs.ErrorType
+	}{
+		{
+			name:     "server error",
+			err:      errs.NewServerError(errs.Internal, cause),
+			wantType: errs.Internal,
+		},
+		{
+			name:     "server error of a client type",
+			err:      errs.NewServerError(errs.Aborted, cause),
+			wantType: errs.Aborted,
+		},
+		{
+			name:     "wrapped plain error",
+			err:      errs.Wrap(cause, "get user", errs.ErrorInfo{"username": "gopher"}),
+			wantType: errs.Unknown,
+		},
+		{
+			name:     "wrapped server error",
+			err:      errs.Wrap(errs.NewServerError(errs.Internal, cause), "get user", nil),
+			wantType: errs.Internal,
+		},
+		{
+			name:     "new without a message",
+			err:      errs.New(errs.NotFound, cause, nil),
+			wantType: errs.NotFound,
+		},
+		{
+			name:     "internal error with a message",
+			err:      errs.NewClientError(errs.Internal, cause, i18n.Message{Fallback: cause.Error()}),
+			wantType: errs.Internal,
+		},
+		{
+			name:     "wrapped internal error with a message",
+			err:      errs.Wrap(errs.NewClientError(errs.Unknown, cause, i18n.Message{Fallback: cause.Error()}), "op", nil),
+			wantType: errs.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Type() != tt.wantType {
+				t.Errorf("type = %s, want %s", tt.err.Type(), tt.wantType)
+			}
+
+			if msg, ok := tt.err.ClientMessage(); ok {
+				t.Errorf("ClientMessage = %+v, want none", msg)
+			}
+
+			got := tt.err.Message()
+			if want := errs.DefaultMessage(tt.wantType).String(); got != want {
+				t.Errorf("Message = %q, want %q", got, want)
+			}
+			for _, secret := range []string{"10.0.0.7", "password", "admin", "get user"} {
+				if strings.Contains(got, secret) {
+					t.Errorf("Message %q exposes %q", got, secret)
+				}
+			}
+
+			// The cause stays available for logging.
+			if !errors.Is(tt.err, cause) {
+				t.Error("cause is not in the error chain")
+			}
+		})
+	}
+}
+
+func TestClientMessageKept(t *testing.T) {
+	msg := i18n.Message{Key: "user.not_found"}
+
+	err := errs.Wrap(errs.NewClientError(errs.NotFound, errors.New("no rows"), msg), "get user", nil)
+
+	got, ok := err.ClientMessage()
+	if !ok || got.Key != msg.Key {
+		t.Fatalf("ClientMessage = %+v, %t, want %+v", got, ok, msg)
+	}
+	if err.Message() != msg.String() {
+		t.Errorf("Message = %q, want %q", err.Message(), msg.String())
+	}
+}
+
+func TestPgErrorTypes(t *testing.T) {
+	pgErr := func(code string) error {
+		return fmt.Errorf("insert: %w", &pgconn.PgError{Code: code, ConstraintName: "users_username_key", TableName: "users"})
+	}
+
+	tests := []struct {
+		name     string
+		err      *errs.Error
+		wantType errs.ErrorType
+	}{
+		{name: "unique violation", err: errs.New(errs.Unknown, pgErr("23505"), nil), wantType: errs.AlreadyExists},
+		{name: "foreign key violation", err: errs.New(errs.Unknown, pgErr("23503"), nil), wantType: errs.FailedPrecondition},
+		{name: "check violation", err: errs.New(errs.Unknown, pgErr("23514"), nil), wantType: errs.FailedValidation},
+		{name: "not-null violation", err: errs.New(errs.Unknown, pgErr("23502"), nil), wantType: errs.FailedValidation},
+		{name: "serialization failure", err: errs.New(errs.Unknown, pgErr("40001"), nil), wantType: errs.Aborted},
+		{name: "deadlock", err: errs.New(errs.Unknown, pgErr("40P01"), nil), wantType: errs.Aborted},
+		{name: "no rows", err: errs.New(errs.Unknown, pgx.ErrNoRows, nil), wantType: errs.NotFound},
+		{name: "unmapped code", err: errs.New(errs.Unknown, pgErr("42P01"), nil), wantType: errs.Unknown},
+		{name: "zero type counts as unknown", err: errs.New(errs.ErrorType{}, pgErr("23505"), nil), wantType: errs.AlreadyExists},
+		{name: "wrapped", err: errs.Wrap(pgErr("23505"), "create user", nil), wantType: errs.AlreadyExists},
+		{name: "explicit type wins", err: errs.New(errs.EditConflict, pgErr("23505"), nil), wantType: errs.EditConflict},
+		{name: "server error stays internal", err: errs.NewServerError(errs.Internal, pgErr("23505")), wantType: errs.Internal},
+		{name: "wrapped typed error keeps its type", err: errs.Wrap(errs.NewServerError(errs.Internal, pgErr("40001")), "op", nil), wantType: errs.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Type() != tt.wantType {
+				t.Errorf("type = %s, want %s", tt.err.Type(), tt.wantType)
+			}
+
+			if tt.wantType == errs.Unknown || tt.wantType == errs.Internal || tt.wantType == errs.EditConflict {
+				return
+			}
+
+			// The database details are for the logs, never the client.
+			if _, ok := tt.err.ClientMessage(); ok {
+				t.Error("mapped pg error has a client message")
+			}
+			if strings.Contains(tt.err.Message(), "users_username_key") {
+				t.Errorf("Message %q exposes the constraint", tt.err.Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package errs
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	pgNotNullViolation     = "23502"
+	pgForeignKeyViolation  = "23503"
+	pgUniqueViolation      = "23505"
+	pgCheckViolation       = "23514"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+var pgTypes = map[string]ErrorType{
+	pgNotNullViolation:     FailedValidation,
+	pgForeignKeyViolation:  FailedPrecondition,
+	pgUniqueViolation:      AlreadyExists,
+	pgCheckViolation:       FailedValidation,
+	pgSerializationFailure: Aborted,
+	pgDeadlockDetected:     Aborted,
+}
+
+func fromPg(err error) (ErrorType, ErrorInfo, bool) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NotFound, nil, true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ErrorType{}, nil, false
+	}
+
+	t, ok := pgTypes[pgErr.Code]
+	if !ok {
+		return ErrorType{}, nil, false
+	}
+
+	fields := ErrorInfo{
+		"pg_code":       pgErr.Code,
+		"pg_constraint": pgErr.ConstraintName,
+		"pg_table":      pgErr.TableName,
+	}
+
+	return t, fields, true
+}
@@ -0,0 +1,195 @@
+// Package feature evaluates feature flags from an in-process snapshot that is
+// refreshed from a backing store.
+package feature
+
+import (
+	"context"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/auth"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+// Flag is one feature flag as seen by the evaluator.
+type Flag struct {
+	Name    string
+	Enabled bool
+
+	// Rollout is the percentage of users an enabled flag is on for. Users
+	// are bucketed by hashing the flag name with their ID, so a user stays
+	// in the same bucket as the percentage grows.
+	Rollout int
+
+	// Users and Roles force the flag on or off for a subject, even when
+	// the flag itself is disabled.
+	Users map[string]bool
+	Roles map[string]bool
+}
+
+// Subject is who a flag is evaluated for. The zero value is an anonymous
+// request.
+type Subject struct {
+	UserID string
+	Roles  []string
+}
+
+// On reports whether the flag is on for s. A user override wins over role
+// overrides, and a role override turning the flag off wins over one turning
+// it on.
+func (f Flag) On(s Subject) bool {
+	if s.UserID != "" {
+		if on, ok := f.Users[s.UserID]; ok {
+			return on
+		}
+	}
+
+	var matched bool
+	for _, role := range s.Roles {
+		on, ok := f.Roles[role]
+		if !ok {
+			continue
+		}
+		if !on {
+			return false
+		}
+		matched = true
+	}
+
+	switch {
+	case matched:
+		return true
+	case !f.Enabled:
+		return false
+	case f.Rollout >= 100:
+		return true
+	case f.Rollout <= 0 || s.UserID == "":
+		return false
+	}
+
+	return bucket(f.Name, s.UserID) < f.Rollout
+}
+
+func bucket(name, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{':'})
+	h.Write([]byte(userID))
+
+	return int(h.Sum32() % 100)
+}
+
+// =============================================================================
+
+// GetSubject returns the subject for the identity authentication attached
+// to ctx.
+func GetSubject(ctx context.Context) Subject {
+	id := auth.GetIdentity(ctx)
+
+	return Subject{
+		UserID: id.UserID,
+		Roles:  id.Roles,
+	}
+}
+
+// =============================================================================
+
+type LoadFn func(ctx context.Context) ([]Flag, error)
+
+type Config struct {
+	Load LoadFn
+
+	// Interval between refreshes done by Run. Defaults to 30 seconds.
+	Interval time.Duration
+
+	// ErrorFn is called when a background refresh fails. The previous
+	// snapshot stays in use.
+	ErrorFn func(ctx context.Context, err error)
+}
+
+// Flags caches every flag in memory so evaluation never touches the store.
+// A nil *Flags reports every flag as off.
+type Flags struct {
+	load     LoadFn
+	interval time.Duration
+	errorFn  func(ctx context.Context, err error)
+
+	flags atomic.Pointer[map[string]Flag]
+}
+
+func New(cfg Config) *Flags {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+
+	f := Flags{
+		load:     cfg.Load,
+		interval: cfg.Interval,
+		errorFn:  cfg.ErrorFn,
+	}
+	f.flags.Store(&map[string]Flag{})
+
+	return &f
+}
+
+// Refresh replaces the snapshot with the current contents of the store.
+func (f *Flags) Refresh(ctx context.Context) error {
+	flags, err := f.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshot := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		snapshot[flag.Name] = flag
+	}
+
+	f.flags.Store(&snapshot)
+
+	return nil
+}
+
+// Run refreshes the snapshot periodically until ctx is canceled.
+func (f *Flags) Run(ctx context.Context) error {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if err := f.Refresh(ctx); err != nil && f.errorFn != nil && ctx.Err() == nil {
+				f.errorFn(ctx, err)
+			}
+		}
+	}
+}
+
+// Enabled reports whether the named flag is on for the subject stored in ctx.
+// Unknown flags are off.
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	return f.EnabledFor(name, GetSubject(ctx))
+}
+
+func (f *Flags) EnabledFor(name string, s Subject) bool {
+	if f == nil {
+		return false
+	}
+
+	flag, ok := (*f.flags.Load())[name]
+	if !ok {
+		return false
+	}
+
+	return flag.On(s)
+}
+
+// Gate returns middleware that hides a route while the named flag is off.
+func (f *Flags) Gate(name string) web.Middleware {
+	return web.Gate(func(ctx context.Context) bool {
+		return f.Enabled(ctx, name)
+	})
+}
@@ -0,0 +1,233 @@
+package feature_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agkmw/reddit-clone/internal/platform/auth"
+	"github.com/agkmw/reddit-clone/internal/platform/feature"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+func TestFlagOn(t *testing.T) {
+	alice := feature.Subject{UserID: "alice", Roles: []string{"moderator", "beta"}}
+	bob := feature.Subject{UserID: "bob", Roles: []string{"beta"}}
+	anonymous := feature.Subject{}
+
+	tests := []struct {
+		name    string
+		flag    feature.Flag
+		subject feature.Subject
+		want    bool
+	}{
+		{
+			name:    "disabled",
+			flag:    feature.Flag{Name: "f", Rollout: 100},
+			subject: bob,
+			want:    false,
+		},
+		{
+			name:    "enabled for everyone",
+			flag:    feature.Flag{Name: "f", Enabled: true, Rollout: 100},
+			subject: anonymous,
+			want:    true,
+		},
+		{
+			name:    "enabled with no rollout",
+			flag:    feature.Flag{Name: "f", Enabled: true},
+			subject: bob,
+			want:    false,
+		},
+		{
+			name:    "partial rollout leaves anonymous requests out",
+			flag:    feature.Flag{Name: "f", Enabled: true, Rollout: 99},
+			subject: anonymous,
+			want:    false,
+		},
+		{
+			name:    "user override turns a disabled flag on",
+			flag:    feature.Flag{Name: "f", Users: map[string]bool{"bob": true}},
+			subject: bob,
+			want:    true,
+		},
+		{
+			name:    "user override turns an enabled flag off",
+			flag:    feature.Flag{Name: "f", Enabled: true, Rollout: 100, Users: map[string]bool{"bob": false}},
+			subject: bob,
+			want:    false,
+		},
+		{
+			name:    "user override wins over a role override",
+			flag:    feature.Flag{Name: "f", Users: map[string]bool{"alice": true}, Roles: map[string]bool{"moderator": false}},
+			subject: alice,
+			want:    true,
+		},
+		{
+			name:    "other users' overrides do not apply",
+			flag:    feature.Flag{Name: "f", Users: map[string]bool{"alice": true}},
+			subject: bob,
+			want:    false,
+		},
+		{
+			name:    "role override turns a disabled flag on",
+			flag:    feature.Flag{Name: "f", Roles: map[string]bool{"beta": true}},
+			subject: bob,
+			want:    true,
+		},
+		{
+			name:    "role override turns an enabled flag off",
+			flag:    feature.Flag{Name: "f", Enabled: true, Rollout: 100, Roles: map[string]bool{"beta": false}},
+			subject: bob,
+			want:    false,
+		},
+		{
+			name:    "a role turning the flag off wins over one turning it on",
+			flag:    feature.Flag{Name: "f", Roles: map[string]bool{"beta": true, "moderator": false}},
+			subject: alice,
+			want:    false,
+		},
+		{
+			name:    "role overrides do not apply to anonymous requests",
+			flag:    feature.Flag{Name: "f", Roles: map[string]bool{"beta": true}},
+			subject: anonymous,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.On(tt.subject); got != tt.want {
+				t.Errorf("On(%+v) = %t, want %t", tt.subject, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolloutBuckets(t *testing.T) {
+	const users = 2000
+
+	on := func(name string, rollout int) map[string]bool {
+		flag := feature.Flag{Name: name, Enabled: true, Rollout: rollout}
+
+		set := make(map[string]bool)
+		for i := range users {
+			id := fmt.Sprintf("user-%d", i)
+			if flag.On(feature.Subject{UserID: id}) {
+				set[id] = true
+			}
+		}
+
+		return set
+	}
+
+	prev := on("search", 0)
+	if len(prev) != 0 {
+		t.Fatalf("rollout 0: %d users on", len(prev))
+	}
+
+	for rollout := 10; rollout <= 100; rollout += 10 {
+		cur := on("search", rollout)
+
+		// Growing the rollout only ever adds users.
+		for id := range prev {
+			if !cur[id] {
+				t.Fatalf("rollout %d: %s dropped out", rollout, id)
+			}
+		}
+
+		// Buckets are spread evenly enough for the percentage to hold.
+		want := users * rollout / 100
+		if got := len(cur); got < want-users/20 || got > want+users/20 {
+			t.Errorf("rollout %d: %d of %d users on, want about %d", rollout, got, users, want)
+		}
+
+		prev = cur
+	}
+
+	// The same user is always in the same bucket for a flag.
+	a, b := on("search", 50), on("search", 50)
+	if len(a) != len(b) {
+		t.Fatalf("repeated evaluation differs: %d vs %d users", len(a), len(b))
+	}
+	for id := range a {
+		if !b[id] {
+			t.Fatalf("repeated evaluation differs for %s", id)
+		}
+	}
+
+	// Different flags bucket users independently.
+	other := on("comments", 50)
+	var same int
+	for i := range users {
+		id := fmt.Sprintf("user-%d", i)
+		if a[id] == other[id] {
+			same++
+		}
+	}
+	if same == users {
+		t.Error("two flags at 50% picked exactly the same users")
+	}
+}
+
+func TestGateUsesIdentity(t *testing.T) {
+	flags := feature.New(feature.Config{
+		Load: func(ctx context.Context) ([]feature.Flag, error) {
+			return []feature.Flag{
+				{Name: "new-search", Users: map[string]bool{"alice": true}, Roles: map[string]bool{"moderator": true}},
+			}, nil
+		},
+	})
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %s", err)
+	}
+
+	// Stands in for authentication, taking the identity from headers.
+	identify := func(handler web.Handler) web.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if user := r.Header.Get("X-User"); user != "" {
+				ctx = auth.WithIdentity(ctx, auth.Identity{UserID: user, Roles: r.Header.Values("X-Role")})
+			}
+			return handler(ctx, w, r)
+		}
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, identify)
+	app.HandlerFuncWithMid(http.MethodGet, "", "/search", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}, flags.Gate("new-search"))
+
+	tests := []struct {
+		name string
+		user string
+		role string
+		want int
+	}{
+		{name: "anonymous", want: http.StatusNotFound},
+		{name: "user without override", user: "bob", want: http.StatusNotFound},
+		{name: "user override", user: "alice", want: http.StatusNoContent},
+		{name: "role override", user: "carol", role: "moderator", want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/search", nil)
+			if tt.user != "" {
+				r.Header.Set("X-User", tt.user)
+			}
+			if tt.role != "" {
+				r.Header.Set("X-Role", tt.role)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+var defaultCatalog = newDefaultCatalog()
+
+// Default returns the catalog with the messages shipped with the service.
+func Default() *Catalog {
+	return defaultCatalog
+}
+
+func newDefaultCatalog() *Catalog {
+	c := NewCatalog(language.English)
+
+	c.Add(language.English, english)
+	c.Add(language.Spanish, spanish)
+
+	return c
+}
+
+var english = map[string]Text{
+	"error.aborted":                      {Other: "the request was aborted due to a conflict, please try again"},
+	"error.already_exists":               {Other: "the resource already exists"},
+	"error.bad_request":                  {Other: "the request body contains invalid JSON"},
+	"error.edit_conflict":                {Other: "unable to modify the resource due to an edit conflict, please try again"},
+	"error.failed_precondition":          {Other: "the resource is not in a state required by the request"},
+	"error.failed_validation":            {Other: "the request failed validation"},
+	"error.inactive_account":             {Other: "your account must be activated to access this resource"},
+	"error.internal":                     {Other: "the server encountered a problem and could not process your request"},
+	"error.invalid_argument":             {Other: "the request contains an invalid argument"},
+	"error.invalid_authentication_token": {Other: "invalid or missing authentication token"},
+	"error.invalid_credentials":          {Other: "invalid authentication credentials"},
+	"error.method_not_allowed":           {Other: "the {method} method is not supported for this resource"},
+	"error.not_found":                    {Other: "the requested resource was not found"},
+	"error.permission_denied":            {Other: "you do not have the permissions to access this resource"},
+	"error.precondition_required":        {Other: "this request must be conditional, send If-Match with the resource's ETag"},
+	"error.request_too_large":            {Other: "the request body is too large"},
+	"error.too_many_requests":            {Other: "too many requests, please try again later"},
+	"error.unauthenticated":              {Other: "authentication is required to access this resource"},
+	"error.unknown":                      {Other: "the server encountered a problem and could not process your request"},
+	"error.unsupported_media_type":       {Other: "the request body must be JSON, sent with Content-Type: application/json"},
+
+	"problem.aborted":                {Other: "Request Aborted"},
+	"problem.already_exists":         {Other: "Resource Already Exists"},
+	"problem.edit_conflict":          {Other: "Edit Conflict"},
+	"problem.failed_precondition":    {Other: "Precondition Failed"},
+	"problem.failed_validation":      {Other: "Validation Failed"},
+	"problem.internal":               {Other: "Internal Server Error"},
+	"problem.invalid_argument":       {Other: "Invalid Argument"},
+	"problem.method_not_allowed":     {Other: "Method Not Allowed"},
+	"problem.not_found":              {Other: "Resource Not Found"},
+	"problem.permission_denied":      {Other: "Permission Denied"},
+	"problem.precondition_required":  {Other: "Precondition Required"},
+	"problem.request_too_large":      {Other: "Request Too Large"},
+	"problem.too_many_requests":      {Other: "Too Many Requests"},
+	"problem.unauthenticated":        {Other: "Authentication Required"},
+	"problem.unknown":                {Other: "Unknown Error"},
+	"problem.unsupported_media_type": {Other: "Unsupported Media Type"},
+
+	"decode.empty":           {Other: "body must not be empty"},
+	"decode.field_type":      {Other: "body contains incorrect JSON type for field {field}"},
+	"decode.malformed":       {Other: "body contains badly-formed JSON"},
+	"decode.multiple_values": {Other: "body must contain only a single JSON value"},
+	"decode.syntax":          {Other: "body contains badly-formed JSON (at character {offset})"},
+	"decode.type":            {Other: "body contains incorrect JSON type (at character {offset})"},
+	"decode.unknown_field":   {Other: "body contains unknown key {field}"},
+
+	"flag.already_exists":     {Other: "a feature flag with this name already exists"},
+	"flag.edit_conflict":      {Other: "unable to update the feature flag due to an edit conflict, please try again"},
+	"flag.not_found":          {Other: "the requested feature flag could not be found"},
+	"flag.override_not_found": {Other: "the requested override could not be found"},
+
+	"idempotency.in_flight":   {Other: "a request with this Idempotency-Key is still in progress, please retry later"},
+	"idempotency.invalid_key": {Other: "Idempotency-Key must be 1 to 255 printable ASCII characters"},
+	"idempotency.key_reused":  {Other: "the Idempotency-Key was already used for a different request"},
+
+	"request.too_large": {
+		One:   "body must not be greater than {count} byte",
+		Other: "body must not be greater than {count} bytes",
+	},
+	"request.unreadable_body": {Other: "unable to read the request body"},
+
+	"user.email_taken":    {Other: "a user with this email address already exists"},
+	"user.not_found":      {Other: "the requested user could not be found"},
+	"user.username_taken": {Other: "a user with this username already exists"},
+
+	"validation.between": {Other: "must be between {min} and {max}"},
+	"validation.email":   {Other: "must be a valid email address"},
+	"validation.matches": {Other: "has an invalid format"},
+	"validation.max_bytes": {
+		One:   "must not be more than {count} byte long",
+		Other: "must not be more than {count} bytes long",
+	},
+	"validation.max_length": {
+		One:   "must not be more than {count} character long",
+		Other: "must not be more than {count} characters long",
+	},
+	"validation.min_length": {
+		One:   "must be at least {count} character long",
+		Other: "must be at least {count} characters long",
+	},
+	"validation.permitted": {Other: "must be one of the permitted values"},
+	"validation.required":  {Other: "must be provided"},
+	"validation.unique":    {Other: "must not contain duplicate values"},
+}
+
+var spanish = map[string]Text{
+	"error.aborted":                      {Other: "la solicitud se canceló debido a un conflicto, inténtalo de nuevo"},
+	"error.already_exists":               {Other: "el recurso ya existe"},
+	"error.bad_request":                  {Other: "el cuerpo de la solicitud contiene JSON no válido"},
+	"error.edit_conflict":                {Other: "no se pudo modificar el recurso debido a un conflicto de edición, inténtalo de nuevo"},
+	"error.failed_precondition":          {Other: "el recurso no está en el estado requerido por la solicitud"},
+	"error.failed_validation":            {Other: "la solicitud no superó la validación"},
+	"error.inactive_account":             {Other: "tu cuenta debe estar activada para acceder a este recurso"},
+	"error.internal":                     {Other: "el servidor tuvo un problema y no pudo procesar tu solicitud"},
+	"error.invalid_argument":             {Other: "la solicitud contiene un argumento no válido"},
+	"error.invalid_authentication_token": {Other: "token de autenticación no válido o ausente"},
+	"error.invalid_credentials":          {Other: "credenciales de autenticación no válidas"},
+	"error.method_not_allowed":           {Other: "el método {method} no está permitido para este recurso"},
+	"error.not_found":                    {Other: "no se encontró el recurso solicitado"},
+	"error.permission_denied":            {Other: "no tienes permiso para acceder a este recurso"},
+	"error.precondition_required":        {Other: "esta solicitud debe ser condicional, envía If-Match con el ETag del recurso"},
+	"error.request_too_large":            {Other: "el cuerpo de la solicitud es demasiado grande"},
+	"error.too_many_requests":            {Other: "demasiadas solicitudes, inténtalo más tarde"},
+	"error.unauthenticated":              {Other: "se requiere autenticación para acceder a este recurso"},
+	"error.unknown":                      {Other: "el servidor tuvo un problema y no pudo procesar tu solicitud"},
+	"error.unsupported_media_type":       {Other: "el cuerpo de la solicitud debe ser JSON, enviado con Content-Type: application/json"},
+
+	"problem.aborted":                {Other: "Solicitud cancelada"},
+	"problem.already_exists":         {Other: "El recurso ya existe"},
+	"problem.edit_conflict":          {Other: "Conflicto de edición"},
+	"problem.failed_precondition":    {Other: "Condición previa fallida"},
+	"problem.failed_validation":      {Other: "Validación fallida"},
+	"problem.internal":               {Other: "Error interno del servidor"},
+	"problem.invalid_argument":       {Other: "Argumento no válido"},
+	"problem.method_not_allowed":     {Other: "Método no permitido"},
+	"problem.not_found":              {Other: "Recurso no encontrado"},
+	"problem.permission_denied":      {Other: "Permiso denegado"},
+	"problem.precondition_required":  {Other: "Condición previa requerida"},
+	"problem.request_too_large":      {Other: "Solicitud demasiado grande"},
+	"problem.too_many_requests":      {Other: "Demasiadas solicitudes"},
+	"problem.unauthenticated":        {Other: "Autenticación requerida"},
+	"problem.unknown":                {Other: "Error desconocido"},
+	"problem.unsupported_media_type": {Other: "Tipo de contenido no admitido"},
+
+	"decode.empty":           {Other: "el cuerpo no debe estar vacío"},
+	"decode.field_type":      {Other: "el cuerpo contiene un tipo JSON incorrecto para el campo {field}"},
+	"decode.malformed":       {Other: "el cuerpo contiene JSON mal formado"},
+	"decode.multiple_values": {Other: "el cuerpo debe contener un único valor JSON"},
+	"decode.syntax":          {Other: "el cuerpo contiene JSON mal formado (en el carácter {offset})"},
+	"decode.type":            {Other: "el cuerpo contiene un tipo JSON incorrecto (en el carácter {offset})"},
+	"decode.unknown_field":   {Other: "el cuerpo contiene la clave desconocida {field}"},
+
+	"flag.already_exists":     {Other: "ya existe un feature flag con este nombre"},
+	"flag.edit_conflict":      {Other: "no se pudo actualizar el feature flag debido a un conflicto de edición, inténtalo de nuevo"},
+	"flag.not_found":          {Other: "no se encontró el feature flag solicitado"},
+	"flag.override_not_found": {Other: "no se encontró la excepción solicitada"},
+
+	"idempotency.in_flight":   {Other: "una solicitud con este Idempotency-Key sigue en curso, inténtalo más tarde"},
+	"idempotency.invalid_key": {Other: "Idempotency-Key debe tener entre 1 y 255 caracteres ASCII imprimibles"},
+	"idempotency.key_reused":  {Other: "el Idempotency-Key ya se usó para otra solicitud"},
+
+	"request.too_large": {
+		One:   "el cuerpo no debe superar {count} byte",
+		Other: "el cuerpo no debe superar {count} bytes",
+	},
+	"request.unreadable_body": {Other: "no se pudo leer el cuerpo de la solicitud"},
+
+	"user.email_taken":    {Other: "ya existe un usuario con este correo electrónico"},
+	"user.not_found":      {Other: "no se encontró el usuario solicitado"},
+	"user.username_taken": {Other: "ya existe un usuario con este nombre de usuario"},
+
+	"validation.between": {Other: "debe estar entre {min} y {max}"},
+	"validation.email":   {Other: "debe ser una dirección de correo válida"},
+	"validation.matches": {Other: "tiene un formato no válido"},
+	"validation.max_bytes": {
+		One:   "no debe ocupar más de {count} byte",
+		Other: "no debe ocupar más de {count} bytes",
+	},
+	"validation.max_length": {
+		One:   "no debe tener más de {count} carácter",
+		Other: "no debe tener más de {count} caracteres",
+	},
+	"validation.min_length": {
+		One:   "debe tener al menos {count} carácter",
+		Other: "debe tener al menos {count} caracteres",
+	},
+	"validation.permitted": {Other: "debe ser uno de los valores permitidos"},
+	"validation.required":  {Other: "es obligatorio"},
+	"validation.unique":    {Other: "no debe contener valores duplicados"},
+}
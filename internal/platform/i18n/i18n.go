@@ -0,0 +1,219 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+type Args map[string]any
+
+// Message identifies a catalog entry. Fallback is rendered when the key is
+// unknown to every language in the catalog, which lets callers pass ad-hoc
+// text through the same path.
+type Message struct {
+	Key      string
+	Args     Args
+	Fallback string
+}
+
+func (m Message) String() string {
+	return defaultCatalog.Localizer(defaultCatalog.fallback).Localize(m)
+}
+
+// Text holds the plural forms of a message. Only Other is required; the rest
+// fall back to it when empty.
+type Text struct {
+	Zero  string
+	One   string
+	Two   string
+	Few   string
+	Many  string
+	Other string
+}
+
+func (t Text) form(f pluralForm) string {
+	var s string
+
+	switch f {
+	case formZero:
+		s = t.Zero
+	case formOne:
+		s = t.One
+	case formTwo:
+		s = t.Two
+	case formFew:
+		s = t.Few
+	case formMany:
+		s = t.Many
+	}
+
+	if s == "" {
+		return t.Other
+	}
+
+	return s
+}
+
+// =============================================================================
+
+type Catalog struct {
+	fallback language.Tag
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages map[language.Tag]map[string]Text
+}
+
+func NewCatalog(fallback language.Tag) *Catalog {
+	c := Catalog{
+		fallback: fallback,
+		messages: make(map[language.Tag]map[string]Text),
+	}
+
+	c.Add(fallback, nil)
+
+	return &c
+}
+
+func (c *Catalog) Add(tag language.Tag, messages map[string]Text) {
+	if _, exists := c.messages[tag]; !exists {
+		c.messages[tag] = make(map[string]Text, len(messages))
+		c.tags = append(c.tags, tag)
+		c.matcher = language.NewMatcher(c.tags)
+	}
+
+	for k, v := range messages {
+		c.messages[tag][k] = v
+	}
+}
+
+// Has reports whether key is in the catalog's fallback language, which every
+// message is expected to have.
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.messages[c.fallback][key]
+	return ok
+}
+
+func (c *Catalog) Supported(lang string) bool {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return false
+	}
+
+	_, _, confidence := c.matcher.Match(tag)
+
+	return confidence >= language.High
+}
+
+// Match picks the best supported language for the given preferences. Each
+// preference may be a single tag or a full Accept-Language header value; the
+// first preference that matches wins.
+func (c *Catalog) Match(prefs ...string) language.Tag {
+	for _, pref := range prefs {
+		if pref == "" {
+			continue
+		}
+
+		tags, _, err := language.ParseAcceptLanguage(pref)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+
+		_, idx, confidence := c.matcher.Match(tags...)
+		if confidence == language.No {
+			continue
+		}
+
+		return c.tags[idx]
+	}
+
+	return c.fallback
+}
+
+func (c *Catalog) Localizer(tag language.Tag) *Localizer {
+	return &Localizer{
+		catalog: c,
+		tag:     tag,
+	}
+}
+
+func (c *Catalog) lookup(tag language.Tag, key string) (Text, language.Tag, bool) {
+	if text, ok := c.messages[tag][key]; ok {
+		return text, tag, true
+	}
+
+	if text, ok := c.messages[c.fallback][key]; ok {
+		return text, c.fallback, true
+	}
+
+	return Text{}, tag, false
+}
+
+// =============================================================================
+
+type Localizer struct {
+	catalog *Catalog
+	tag     language.Tag
+}
+
+func (l *Localizer) Language() language.Tag {
+	return l.tag
+}
+
+// SetLanguage overrides the negotiated language, e.g. with a preference stored
+// on the authenticated user. Unsupported values leave the language unchanged.
+func (l *Localizer) SetLanguage(lang string) {
+	if !l.catalog.Supported(lang) {
+		return
+	}
+
+	l.tag = l.catalog.Match(lang)
+}
+
+func (l *Localizer) Localize(m Message) string {
+	text, tag, ok := l.catalog.lookup(l.tag, m.Key)
+	if !ok {
+		switch {
+		case m.Fallback != "":
+			return render(m.Fallback, m.Args)
+		default:
+			return m.Key
+		}
+	}
+
+	form := formOther
+	if n, ok := count(m.Args); ok {
+		form = pluralFormFor(tag, n)
+	}
+
+	return render(text.form(form), m.Args)
+}
+
+func render(tmpl string, args Args) string {
+	if len(args) == 0 {
+		return tmpl
+	}
+
+	pairs := make([]string, 0, len(args)*2)
+	for k, v := range args {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+func count(args Args) (int, bool) {
+	switch n := args["count"].(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case uint:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
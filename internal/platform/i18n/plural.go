@@ -0,0 +1,77 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+type pluralForm int
+
+const (
+	formOther pluralForm = iota
+	formZero
+	formOne
+	formTwo
+	formFew
+	formMany
+)
+
+// pluralFormFor implements the CLDR cardinal rules for integers in the
+// languages we are likely to ship. Anything unknown uses the English rule.
+func pluralFormFor(tag language.Tag, n int) pluralForm {
+	if n < 0 {
+		n = -n
+	}
+
+	base, _ := tag.Base()
+
+	switch base.String() {
+	case "ja", "ko", "my", "th", "vi", "zh", "id":
+		return formOther
+
+	case "fr", "pt":
+		if n == 0 || n == 1 {
+			return formOne
+		}
+		return formOther
+
+	case "ru", "uk", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return formOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return formFew
+		default:
+			return formMany
+		}
+
+	case "pl":
+		switch {
+		case n == 1:
+			return formOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return formFew
+		default:
+			return formMany
+		}
+
+	case "ar":
+		switch {
+		case n == 0:
+			return formZero
+		case n == 1:
+			return formOne
+		case n == 2:
+			return formTwo
+		case n%100 >= 3 && n%100 <= 10:
+			return formFew
+		case n%100 >= 11:
+			return formMany
+		default:
+			return formOther
+		}
+
+	default:
+		if n == 1 {
+			return formOne
+		}
+		return formOther
+	}
+}
@@ -0,0 +1,53 @@
+// Package idempotency describes the storage behind Idempotency-Key support:
+// the first request with a key claims it, and retries with the same key get
+// the stored response instead of running again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Record is what is stored for a key.
+type Record struct {
+	// Fingerprint identifies the request that claimed the key, so that a
+	// key reused for a different request can be refused.
+	Fingerprint string
+
+	// Done is false while the request that claimed the key is running.
+	Done bool
+
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps records per scope, usually a user, and key. Implementations
+// must be safe for concurrent use across replicas.
+type Store interface {
+	// Claim records fingerprint under the key and reports true when the
+	// caller now owns it. Otherwise it returns the existing record. A key
+	// whose owner has held it past lease, or whose record is older than ttl,
+	// can be claimed again.
+	Claim(ctx context.Context, scope, key, fingerprint string, lease, ttl time.Duration) (Record, bool, error)
+
+	// Complete stores the response for a claimed key.
+	Complete(ctx context.Context, scope, key string, rec Record) error
+
+	// Release gives up a claimed key without a response so that the request
+	// can be retried.
+	Release(ctx context.Context, scope, key string) error
+}
+
+// Fingerprint hashes the parts of a request that must match for a retry to
+// be the same request.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
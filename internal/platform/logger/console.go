@@ -0,0 +1,249 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatConsole Format = "console"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatJSON, FormatConsole:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q", s)
+	}
+}
+
+const (
+	colorReset  = "\x1b[0m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+)
+
+const (
+	consoleFileWidth = 20
+	consoleMsgWidth  = 32
+	consoleTraceLen  = 8
+)
+
+// consoleHandler writes one aligned, optionally colored line per record for
+// reading logs in a terminal during development.
+type consoleHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	color bool
+
+	attrs  []byte
+	prefix string
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler, color bool) *consoleHandler {
+	return &consoleHandler{
+		mu:    &sync.Mutex{},
+		w:     w,
+		level: level,
+		color: color,
+	}
+}
+
+func (h *consoleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(h.paint(colorDim, r.Time.Format(time.TimeOnly+".000")))
+	buf.WriteByte(' ')
+
+	levelColor, levelName := consoleLevel(r.Level)
+	buf.WriteString(h.paint(levelColor, levelName))
+	buf.WriteByte(' ')
+
+	buf.WriteString(h.paint(colorDim, pad(sourceFile(r.PC), consoleFileWidth)))
+	buf.WriteByte(' ')
+
+	buf.WriteString(pad(r.Message, consoleMsgWidth))
+
+	buf.Write(h.attrs)
+
+	var traceID string
+
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "trace_id" && h.prefix == "" {
+			traceID = a.Value.String()
+			return true
+		}
+
+		h.appendAttr(&buf, h.prefix, a)
+		return true
+	})
+
+	if traceID = strings.Trim(traceID, "0"); traceID != "" {
+		if len(traceID) > consoleTraceLen {
+			traceID = traceID[:consoleTraceLen]
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(h.paint(colorDim, "trace="+traceID))
+	}
+
+	line := append(bytes.TrimRight(buf.Bytes(), " "), '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.w.Write(line)
+
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	buf := bytes.NewBuffer(append([]byte(nil), h.attrs...))
+	for _, a := range attrs {
+		h.appendAttr(buf, h.prefix, a)
+	}
+
+	h2 := *h
+	h2.attrs = buf.Bytes()
+
+	return &h2
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+func (h *consoleHandler) appendAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(buf, prefix, ga)
+		}
+
+		return
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(h.paint(colorCyan, prefix+a.Key+"="))
+	buf.WriteString(consoleValue(a.Value))
+}
+
+func (h *consoleHandler) paint(color, s string) string {
+	if !h.color {
+		return s
+	}
+
+	return color + s + colorReset
+}
+
+// =============================================================================
+
+func consoleLevel(level slog.Level) (string, string) {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed, "ERR"
+	case level >= slog.LevelWarn:
+		return colorYellow, "WRN"
+	case level >= slog.LevelInfo:
+		return colorGreen, "INF"
+	default:
+		return colorBlue, "DBG"
+	}
+}
+
+func consoleValue(v slog.Value) string {
+	var s string
+
+	switch v.Kind() {
+	case slog.KindString:
+		s = v.String()
+	case slog.KindTime:
+		s = v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			s = err.Error()
+			break
+		}
+		s = fmt.Sprint(v.Any())
+	default:
+		s = v.String()
+	}
+
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sourceFile(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+
+	frames := runtime.CallersFrames([]uintptr{pc})
+	f, _ := frames.Next()
+
+	return fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+}
+
+func pad(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	return s + strings.Repeat(" ", width-len(s))
+}
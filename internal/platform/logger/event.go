@@ -15,6 +15,20 @@ const (
 	LevelError = Level(slog.LevelError)
 )
 
+// ParseLevel parses names such as "debug", "INFO" or "warn+2".
+func ParseLevel(s string) (Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, err
+	}
+
+	return Level(l), nil
+}
+
+func (l Level) String() string {
+	return slog.Level(l).String()
+}
+
 // =============================================================================
 
 type EventHandler func(ctx context.Context, r Record)
@@ -15,21 +15,64 @@ type TraceIDFn func(ctx context.Context) string
 
 type Logger struct {
 	handler   slog.Handler
+	level     *slog.LevelVar
+	sampler   *sampler
 	traceIDFn TraceIDFn
 }
 
+type Config struct {
+	MinLevel    Level
+	ServiceName string
+	TraceIDFn   TraceIDFn
+	Events      Events
+
+	// Redaction defaults to DefaultRedaction when nil. Pass an empty
+	// Redaction to log values verbatim.
+	Redaction *Redaction
+
+	// Sampling is keyed by message.
+	Sampling map[string]Sampling
+
+	// Format defaults to FormatJSON. Color only applies to FormatConsole.
+	Format Format
+	Color  bool
+}
+
 func New(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn) *Logger {
-	return new(w, minLevel, serviceName, traceIDFn, Events{})
+	return new(w, Config{
+		MinLevel:    minLevel,
+		ServiceName: serviceName,
+		TraceIDFn:   traceIDFn,
+	})
 }
 
 func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
-	return new(w, minLevel, serviceName, traceIDFn, events)
+	return new(w, Config{
+		MinLevel:    minLevel,
+		ServiceName: serviceName,
+		TraceIDFn:   traceIDFn,
+		Events:      events,
+	})
+}
+
+func NewWithConfig(w io.Writer, cfg Config) *Logger {
+	return new(w, cfg)
 }
 
 func NewStdLogger(log *Logger, level Level) *log.Logger {
 	return slog.NewLogLogger(log.handler, slog.Level(level))
 }
 
+// SetLevel changes the minimum level at runtime. It is safe to call while
+// other goroutines are logging.
+func (log *Logger) SetLevel(level Level) {
+	log.level.Set(slog.Level(level))
+}
+
+func (log *Logger) Level() Level {
+	return Level(log.level.Level())
+}
+
 func (log *Logger) Debug(ctx context.Context, msg string, args ...any) {
 	log.write(ctx, 3, LevelDebug, msg, args...)
 }
@@ -69,19 +112,25 @@ func (log *Logger) write(ctx context.Context, caller int, level Level, msg strin
 		return
 	}
 
+	now := time.Now()
+
+	if !log.sampler.allow(level, msg, now) {
+		return
+	}
+
 	var pc [1]uintptr
 	runtime.Callers(caller, pc[:])
 
-	r := slog.NewRecord(time.Now(), slogLevel, msg, pc[0])
+	r := slog.NewRecord(now, slogLevel, msg, pc[0])
 
-	args = append(args, "trace id", log.traceIDFn(ctx))
+	args = append(args, "trace_id", log.traceIDFn(ctx))
 
 	r.Add(args...)
 
 	log.handler.Handle(ctx, r)
 }
 
-func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
+func new(w io.Writer, cfg Config) *Logger {
 	f := func(groups []string, a slog.Attr) slog.Attr {
 		if a.Key == slog.SourceKey {
 			source, ok := a.Value.Any().(*slog.Source)
@@ -94,24 +143,51 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 		return a
 	}
 
-	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{
-		AddSource:   true,
-		Level:       slog.Level(minLevel),
-		ReplaceAttr: f,
-	}))
+	var level slog.LevelVar
+	level.Set(slog.Level(cfg.MinLevel))
+
+	var handler slog.Handler
+
+	switch cfg.Format {
+	case FormatConsole:
+		handler = newConsoleHandler(w, &level, cfg.Color)
+
+	default:
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{
+			AddSource:   true,
+			Level:       &level,
+			ReplaceAttr: f,
+		})
+	}
 
-	if events.Debug != nil || events.Info != nil || events.Warn != nil || events.Error != nil {
+	if events := cfg.Events; events.Debug != nil || events.Info != nil || events.Warn != nil || events.Error != nil {
 		handler = newLogHandler(handler, events)
 	}
 
-	attrs := []slog.Attr{
-		{Key: "service", Value: slog.StringValue(serviceName)},
+	// Redaction wraps the event hooks too, so alerts never carry secrets.
+	redaction := DefaultRedaction
+	if cfg.Redaction != nil {
+		redaction = *cfg.Redaction
+	}
+
+	if !redaction.empty() {
+		handler = newRedactHandler(handler, redaction)
 	}
 
-	handler = handler.WithAttrs(attrs)
+	// The console is read by the person running the service, so the
+	// service name would only be noise there.
+	if cfg.Format != FormatConsole {
+		attrs := []slog.Attr{
+			{Key: "service", Value: slog.StringValue(cfg.ServiceName)},
+		}
+
+		handler = handler.WithAttrs(attrs)
+	}
 
 	return &Logger{
 		handler:   handler,
-		traceIDFn: traceIDFn,
+		level:     &level,
+		sampler:   newSampler(cfg.Sampling),
+		traceIDFn: cfg.TraceIDFn,
 	}
 }
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redaction hides sensitive values before a record reaches the output or any
+// event hook.
+type Redaction struct {
+	// Keys are matched against attribute keys ignoring case and the
+	// separators "_", "-", "." and " ". An attribute whose key contains one
+	// of them has its whole value replaced.
+	Keys []string
+
+	// Patterns are replaced in the message and in string and error values.
+	// A first capture group is kept in front of the replacement and a second
+	// one after it, so only the secret part of a match is hidden.
+	Patterns []*regexp.Regexp
+}
+
+// DefaultRedaction covers credentials, email addresses and bearer tokens.
+var DefaultRedaction = Redaction{
+	Keys: []string{
+		"password",
+		"passwd",
+		"secret",
+		"token",
+		"authorization",
+		"cookie",
+		"apikey",
+		"dsn",
+	},
+	Patterns: []*regexp.Regexp{
+		regexp.MustCompile(`[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)+`),
+		regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9._~+/=-]+`),
+		regexp.MustCompile(`eyJ[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+`),
+		regexp.MustCompile(`(?i)([?&](?:token|access_token|api_key|apikey|password|secret)=)[^&\s]+`),
+		regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+(@)`),
+	},
+}
+
+// With returns a copy of r that also redacts the given keys.
+func (r Redaction) With(keys ...string) Redaction {
+	r.Keys = append(append([]string(nil), r.Keys...), keys...)
+	return r
+}
+
+func (r Redaction) empty() bool {
+	return len(r.Keys) == 0 && len(r.Patterns) == 0
+}
+
+func (r Redaction) redactKey(key string) bool {
+	key = normalizeKey(key)
+
+	for _, k := range r.Keys {
+		if k = normalizeKey(k); k != "" && strings.Contains(key, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Redaction) redactString(s string) string {
+	for _, rx := range r.Patterns {
+		switch rx.NumSubexp() {
+		case 0:
+			s = rx.ReplaceAllLiteralString(s, redactedValue)
+		case 1:
+			s = rx.ReplaceAllString(s, "${1}"+redactedValue)
+		default:
+			s = rx.ReplaceAllString(s, "${1}"+redactedValue+"${2}")
+		}
+	}
+
+	return s
+}
+
+func (r Redaction) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if r.redactKey(a.Key) {
+		return slog.String(a.Key, redactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.redactString(a.Value.String()))
+
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+
+		redacted := make([]slog.Attr, len(attrs))
+		for i, ga := range attrs {
+			redacted[i] = r.redactAttr(ga)
+		}
+
+		a.Value = slog.GroupValue(redacted...)
+
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(r.redactString(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(r.redactString(v.String()))
+		}
+	}
+
+	return a
+}
+
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// =============================================================================
+
+type redactHandler struct {
+	handler   slog.Handler
+	redaction Redaction
+}
+
+func newRedactHandler(handler slog.Handler, redaction Redaction) *redactHandler {
+	return &redactHandler{
+		handler:   handler,
+		redaction: redaction,
+	}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, h.redaction.redactString(r.Message), r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redaction.redactAttr(a))
+		return true
+	})
+
+	return h.handler.Handle(ctx, nr)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redaction.redactAttr(a)
+	}
+
+	return &redactHandler{
+		handler:   h.handler.WithAttrs(redacted),
+		redaction: h.redaction,
+	}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{
+		handler:   h.handler.WithGroup(name),
+		redaction: h.redaction,
+	}
+}
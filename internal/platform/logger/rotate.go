@@ -0,0 +1,401 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+type FileConfig struct {
+	Path string
+
+	// MaxSize rotates the file before a write would take it past this many
+	// bytes. Zero disables size based rotation.
+	MaxSize int64
+
+	// MaxAge rotates the file once it is this old. A file's age counts from
+	// the rotation that started it, as recorded in the newest backup's name,
+	// so restarts do not reset it. A non-empty file with no backups to date
+	// it is rotated on the first write. Zero disables age based rotation.
+	MaxAge time.Duration
+
+	// MaxBackups is how many rotated files are kept. Zero keeps them all.
+	MaxBackups int
+
+	// Compress gzips rotated files in the background.
+	Compress bool
+
+	// OnError reports rotation and compression failures, after which the
+	// writer carries on with the file it has. It must not write to the
+	// FileWriter. Defaults to printing to stderr.
+	OnError func(err error)
+}
+
+// rotateRetry is how long a failed rotation waits before the next attempt,
+// so that a persistent failure is not retried and reported on every write.
+const rotateRetry = time.Minute
+
+// FileWriter is an io.Writer for log output that rotates its file by size
+// and age. Rotated files are named after the original with a timestamp,
+// e.g. api-20240102T150405.000.log, and optionally gzipped.
+type FileWriter struct {
+	cfg FileConfig
+
+	mu        sync.Mutex
+	file      *os.File
+	size      int64
+	startedAt time.Time
+	retryAt   time.Time
+	closed    bool
+
+	// pending holds rotated files for the maintenance goroutine, which
+	// compresses and prunes them one at a time. It is guarded by mu.
+	pending []string
+	wake    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func NewFileWriter(cfg FileConfig) (*FileWriter, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("log file path is required")
+	}
+
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "log file %s: %s\n", cfg.Path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+
+	fw := FileWriter{
+		cfg:  cfg,
+		wake: make(chan struct{}, 1),
+	}
+
+	f, size, err := fw.open()
+	if err != nil {
+		return nil, err
+	}
+	fw.use(f, size)
+
+	fw.wg.Go(fw.maintain)
+
+	return &fw, nil
+}
+
+func (fw *FileWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.closed {
+		return 0, os.ErrClosed
+	}
+
+	if fw.shouldRotate(len(p)) {
+		if err := fw.rotate(); err != nil {
+			// Losing the line would hide the failure as well, so it goes
+			// to the current file and rotation is retried later.
+			fw.retryAt = time.Now().Add(rotateRetry)
+			fw.cfg.OnError(err)
+		}
+	}
+
+	n, err := fw.file.Write(p)
+	fw.size += int64(n)
+
+	return n, err
+}
+
+// Rotate moves the current file aside and starts a new one.
+func (fw *FileWriter) Rotate() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.closed {
+		return os.ErrClosed
+	}
+
+	return fw.rotate()
+}
+
+// Reopen reopens the file at the configured path. Call it after an external
+// tool such as logrotate has moved the file. If the file cannot be opened,
+// writes carry on to the current one and the error is returned.
+func (fw *FileWriter) Reopen() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.closed {
+		return os.ErrClosed
+	}
+
+	f, size, err := fw.open()
+	if err != nil {
+		return err
+	}
+
+	old := fw.file
+	fw.use(f, size)
+
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("closing previous log file: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the file and waits for background compression to finish.
+func (fw *FileWriter) Close() error {
+	fw.mu.Lock()
+
+	if fw.closed {
+		fw.mu.Unlock()
+		return nil
+	}
+
+	fw.closed = true
+	err := fw.file.Close()
+	close(fw.wake)
+
+	fw.mu.Unlock()
+
+	fw.wg.Wait()
+
+	return err
+}
+
+// =============================================================================
+
+func (fw *FileWriter) open() (*os.File, int64, error) {
+	f, err := os.OpenFile(fw.cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening log file: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("opening log file: %w", err)
+	}
+
+	return f, fi.Size(), nil
+}
+
+// use makes f the current file and dates it for MaxAge.
+func (fw *FileWriter) use(f *os.File, size int64) {
+	fw.file = f
+	fw.size = size
+	fw.retryAt = time.Time{}
+
+	switch backups := fw.backups(); {
+	case size == 0:
+		fw.startedAt = time.Now()
+	case len(backups) > 0:
+		fw.startedAt = backups[len(backups)-1].stamp
+	default:
+		// The file's age is unknown, so it counts as due.
+		fw.startedAt = time.Time{}
+	}
+}
+
+func (fw *FileWriter) shouldRotate(n int) bool {
+	if time.Now().Before(fw.retryAt) {
+		return false
+	}
+
+	if fw.cfg.MaxSize > 0 && fw.size > 0 && fw.size+int64(n) > fw.cfg.MaxSize {
+		return true
+	}
+
+	if fw.cfg.MaxAge > 0 && time.Since(fw.startedAt) >= fw.cfg.MaxAge {
+		return true
+	}
+
+	return false
+}
+
+// rotate moves the file aside and opens a new one. The current file stays
+// in use until the new one is open, so a failure leaves it as it was.
+func (fw *FileWriter) rotate() error {
+	now := time.Now()
+
+	backup := fw.backupName(now)
+	if err := os.Rename(fw.cfg.Path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+
+	f, _, err := fw.open()
+	if err != nil {
+		if rerr := os.Rename(backup, fw.cfg.Path); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("restoring log file: %w", rerr))
+		}
+
+		return err
+	}
+
+	old := fw.file
+
+	fw.file = f
+	fw.size = 0
+	fw.startedAt = now
+	fw.retryAt = time.Time{}
+
+	fw.pending = append(fw.pending, backup)
+	select {
+	case fw.wake <- struct{}{}:
+	default:
+	}
+
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("closing rotated log file: %w", err)
+	}
+
+	return nil
+}
+
+// maintain compresses and prunes rotated files until Close. Doing both in
+// one goroutine keeps pruning from counting or removing a file that is
+// still being compressed.
+func (fw *FileWriter) maintain() {
+	for range fw.wake {
+		fw.mu.Lock()
+		pending := fw.pending
+		fw.pending = nil
+		fw.mu.Unlock()
+
+		if fw.cfg.Compress {
+			for _, path := range pending {
+				if err := compress(path); err != nil {
+					fw.cfg.OnError(err)
+				}
+			}
+		}
+
+		fw.prune()
+	}
+}
+
+func (fw *FileWriter) backupName(t time.Time) string {
+	dir, prefix, ext := fw.parts()
+	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, t.UTC().Format(backupTimeFormat), ext))
+}
+
+func (fw *FileWriter) parts() (dir, prefix, ext string) {
+	dir = filepath.Dir(fw.cfg.Path)
+	base := filepath.Base(fw.cfg.Path)
+	ext = filepath.Ext(base)
+
+	return dir, strings.TrimSuffix(base, ext), ext
+}
+
+type backup struct {
+	stamp time.Time
+	names []string
+}
+
+// backups lists the rotated files, oldest first. A backup and its gzipped
+// copy, left behind by an interrupted compression, count as one.
+func (fw *FileWriter) backups() []backup {
+	dir, prefix, ext := fw.parts()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	byStamp := make(map[string]*backup)
+
+	for _, e := range entries {
+		name := e.Name()
+		raw, ok := strings.CutPrefix(name, prefix+"-")
+		if !ok {
+			continue
+		}
+
+		raw = strings.TrimSuffix(strings.TrimSuffix(raw, ".gz"), ext)
+
+		stamp, err := time.Parse(backupTimeFormat, raw)
+		if err != nil {
+			continue
+		}
+
+		b, ok := byStamp[raw]
+		if !ok {
+			b = &backup{stamp: stamp}
+			byStamp[raw] = b
+		}
+		b.names = append(b.names, name)
+	}
+
+	backups := make([]backup, 0, len(byStamp))
+	for _, raw := range slices.Sorted(maps.Keys(byStamp)) {
+		// Timestamps sort lexically, so the oldest backups come first.
+		backups = append(backups, *byStamp[raw])
+	}
+
+	return backups
+}
+
+// prune removes the oldest backups beyond MaxBackups. Errors are ignored;
+// the next rotation tries again.
+func (fw *FileWriter) prune() {
+	if fw.cfg.MaxBackups <= 0 {
+		return
+	}
+
+	dir, _, _ := fw.parts()
+
+	backups := fw.backups()
+
+	for len(backups) > fw.cfg.MaxBackups {
+		for _, name := range backups[0].names {
+			os.Remove(filepath.Join(dir, name))
+		}
+		backups = backups[1:]
+	}
+}
+
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("compressing rotated log file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("compressing rotated log file: %w", err)
+	}
+
+	gz := gzip.NewWriter(dst)
+
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(path + ".gz")
+		return fmt.Errorf("compressing rotated log file: %w", err)
+	}
+
+	os.Remove(path)
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+)
+
+func TestReopenKeepsFileOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "api.log")
+
+	fw, err := logger.NewFileWriter(logger.FileConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewFileWriter: %s", err)
+	}
+	defer fw.Close()
+
+	// Replacing the directory with a file makes reopening fail.
+	moved := filepath.Join(dir, "moved")
+	if err := os.Rename(filepath.Dir(path), moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Dir(path), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fw.Reopen(); err == nil {
+		t.Fatal("Reopen: expected an error")
+	}
+
+	if _, err := fw.Write([]byte("still here\n")); err != nil {
+		t.Fatalf("Write after failed Reopen: %s", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(moved, "api.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "still here\n" {
+		t.Errorf("log file = %q, want %q", got, "still here\n")
+	}
+}
+
+func TestRotateFailureKeepsWriting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "api.log")
+
+	var reported []error
+	fw, err := logger.NewFileWriter(logger.FileConfig{
+		Path:    path,
+		MaxSize: 10,
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	if err != nil {
+		t.Fatalf("NewFileWriter: %s", err)
+	}
+	defer fw.Close()
+
+	if _, err := fw.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing the directory with a file makes rotation fail.
+	moved := filepath.Join(dir, "moved")
+	if err := os.Rename(filepath.Dir(path), moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Dir(path), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := fw.Write([]byte("x")); err != nil {
+			t.Fatalf("Write: %s", err)
+		}
+	}
+
+	if len(reported) != 1 {
+		t.Errorf("reported %d errors, want 1 until the retry delay passes: %v", len(reported), reported)
+	}
+
+	got, err := os.ReadFile(filepath.Join(moved, "api.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "0123456789xx" {
+		t.Errorf("log file = %q, want %q", got, "0123456789xx")
+	}
+}
+
+func TestRotatePrune(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{name: "plain"},
+		{name: "compressed", compress: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "api.log")
+
+			// A backup whose compression was interrupted counts once.
+			old := time.Now().Add(-time.Hour).UTC().Format("20060102T150405.000")
+			for _, name := range []string{"api-" + old + ".log", "api-" + old + ".log.gz"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fw, err := logger.NewFileWriter(logger.FileConfig{
+				Path:       path,
+				MaxBackups: 2,
+				Compress:   tt.compress,
+				OnError:    func(err error) { t.Errorf("OnError: %s", err) },
+			})
+			if err != nil {
+				t.Fatalf("NewFileWriter: %s", err)
+			}
+
+			for i := range 3 {
+				if _, err := fw.Write([]byte("line\n")); err != nil {
+					t.Fatal(err)
+				}
+				if err := fw.Rotate(); err != nil {
+					t.Fatalf("Rotate %d: %s", i, err)
+				}
+				// Backup names are stamped to the millisecond.
+				time.Sleep(2 * time.Millisecond)
+			}
+
+			if err := fw.Close(); err != nil {
+				t.Fatalf("Close: %s", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var backups []string
+			for _, e := range entries {
+				if e.Name() != "api.log" {
+					backups = append(backups, e.Name())
+				}
+			}
+
+			if len(backups) != 2 {
+				t.Fatalf("backups = %v, want 2", backups)
+			}
+			if slices.ContainsFunc(backups, func(name string) bool { return strings.Contains(name, old) }) {
+				t.Errorf("backups = %v, want the oldest removed", backups)
+			}
+			for _, name := range backups {
+				if strings.HasSuffix(name, ".gz") != tt.compress {
+					t.Errorf("backup %s, compress = %t", name, tt.compress)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxAgeFromBackup(t *testing.T) {
+	tests := []struct {
+		name       string
+		backupAge  time.Duration
+		noBackup   bool
+		wantRotate bool
+	}{
+		{name: "recent backup", backupAge: time.Minute},
+		{name: "old backup", backupAge: 2 * time.Hour, wantRotate: true},
+		{name: "no backup", noBackup: true, wantRotate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "api.log")
+
+			if err := os.WriteFile(path, []byte("earlier run\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.noBackup {
+				stamp := time.Now().Add(-tt.backupAge).UTC().Format("20060102T150405.000")
+				if err := os.WriteFile(filepath.Join(dir, "api-"+stamp+".log"), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fw, err := logger.NewFileWriter(logger.FileConfig{Path: path, MaxAge: time.Hour})
+			if err != nil {
+				t.Fatalf("NewFileWriter: %s", err)
+			}
+
+			if _, err := fw.Write([]byte("this run\n")); err != nil {
+				t.Fatal(err)
+			}
+			if err := fw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := "earlier run\nthis run\n"
+			if tt.wantRotate {
+				want = "this run\n"
+			}
+			if string(got) != want {
+				t.Errorf("log file = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sampling limits how often one message is logged. Within every Tick the
+// first First records are logged and after that every Thereafter-th one; a
+// zero Thereafter drops the rest. Errors are never sampled.
+type Sampling struct {
+	First      int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// ParseSampling parses a list of "message=first/thereafter[/tick]" entries
+// separated by ";", e.g. "request started=100/10;request completed=100/10/1s".
+// Tick defaults to one second.
+func ParseSampling(spec string) (map[string]Sampling, error) {
+	sampling := make(map[string]Sampling)
+
+	for entry := range strings.SplitSeq(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		msg, rule, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(msg) == "" {
+			return nil, fmt.Errorf("sampling %q: want message=first/thereafter[/tick]", entry)
+		}
+
+		parts := strings.Split(rule, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("sampling %q: want message=first/thereafter[/tick]", entry)
+		}
+
+		var s Sampling
+		var err error
+
+		if s.First, err = strconv.Atoi(parts[0]); err != nil || s.First < 0 {
+			return nil, fmt.Errorf("sampling %q: invalid first %q", entry, parts[0])
+		}
+		if s.Thereafter, err = strconv.Atoi(parts[1]); err != nil || s.Thereafter < 0 {
+			return nil, fmt.Errorf("sampling %q: invalid thereafter %q", entry, parts[1])
+		}
+		if len(parts) == 3 {
+			if s.Tick, err = time.ParseDuration(parts[2]); err != nil || s.Tick <= 0 {
+				return nil, fmt.Errorf("sampling %q: invalid tick %q", entry, parts[2])
+			}
+		}
+
+		sampling[strings.TrimSpace(msg)] = s
+	}
+
+	return sampling, nil
+}
+
+// =============================================================================
+
+type sampler struct {
+	rules map[string]Sampling
+
+	mu       sync.Mutex
+	counters map[string]*sampleCounter
+}
+
+type sampleCounter struct {
+	resetAt time.Time
+	n       int
+}
+
+func newSampler(rules map[string]Sampling) *sampler {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return &sampler{
+		rules:    rules,
+		counters: make(map[string]*sampleCounter, len(rules)),
+	}
+}
+
+func (s *sampler) allow(level Level, msg string, now time.Time) bool {
+	if s == nil || level >= LevelError {
+		return true
+	}
+
+	rule, ok := s.rules[msg]
+	if !ok {
+		return true
+	}
+
+	tick := rule.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[msg]
+	if !ok {
+		c = &sampleCounter{}
+		s.counters[msg] = c
+	}
+
+	if !now.Before(c.resetAt) {
+		c.resetAt = now.Add(tick)
+		c.n = 0
+	}
+
+	c.n++
+
+	switch {
+	case c.n <= rule.First:
+		return true
+	case rule.Thereafter <= 0:
+		return false
+	default:
+		return (c.n-rule.First)%rule.Thereafter == 0
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"sync/atomic"
+)
+
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// =============================================================================
+
+type GaugeVec struct {
+	metricName string
+	help       string
+	vec        *vec[*Gauge]
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := GaugeVec{
+		metricName: name,
+		help:       help,
+		vec:        newVec(labels, func() *Gauge { return &Gauge{} }),
+	}
+
+	r.register(&g)
+
+	return &g
+}
+
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	return r.NewGaugeVec(name, help).With()
+}
+
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return g.vec.with(values...)
+}
+
+func (g *GaugeVec) name() string {
+	return g.metricName
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	writeHeader(w, g.metricName, g.help, "gauge")
+
+	g.vec.each(func(values []string, m *Gauge) {
+		writeSample(w, g.metricName, g.vec.labels, values, m.Value())
+	})
+}
+
+// =============================================================================
+
+// valueFunc is a metric whose value is read at scrape time, e.g. from
+// pgxpool.Stat or runtime.MemStats.
+type valueFunc struct {
+	metricName string
+	help       string
+	typ        string
+	fn         func() float64
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&valueFunc{metricName: name, help: help, typ: "gauge", fn: fn})
+}
+
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&valueFunc{metricName: name, help: help, typ: "counter", fn: fn})
+}
+
+func (f *valueFunc) name() string {
+	return f.metricName
+}
+
+func (f *valueFunc) write(w *bufio.Writer) {
+	writeHeader(w, f.metricName, f.help, f.typ)
+	writeSample(w, f.metricName, nil, nil, f.fn())
+}
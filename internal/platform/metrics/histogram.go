@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"slices"
+	"sync"
+)
+
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type Histogram struct {
+	upperBounds []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		upperBounds: buckets,
+		counts:      make([]uint64, len(buckets)),
+	}
+}
+
+func (h *Histogram) Observe(v float64) {
+	i, _ := slices.BinarySearch(h.upperBounds, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// =============================================================================
+
+type HistogramVec struct {
+	metricName string
+	help       string
+	vec        *vec[*Histogram]
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	h := HistogramVec{
+		metricName: name,
+		help:       help,
+		vec:        newVec(labels, func() *Histogram { return newHistogram(buckets) }),
+	}
+
+	r.register(&h)
+
+	return &h
+}
+
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.vec.with(values...)
+}
+
+func (h *HistogramVec) name() string {
+	return h.metricName
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, h.metricName, h.help, "histogram")
+
+	labels := append(slices.Clone(h.vec.labels), "le")
+
+	h.vec.each(func(values []string, m *Histogram) {
+		m.mu.Lock()
+		counts := slices.Clone(m.counts)
+		sum, count := m.sum, m.count
+		m.mu.Unlock()
+
+		var cumulative uint64
+		for i, bound := range m.upperBounds {
+			cumulative += counts[i]
+			writeSample(w, h.metricName+"_bucket", labels, append(slices.Clone(values), formatFloat(bound)), float64(cumulative))
+		}
+		writeSample(w, h.metricName+"_bucket", labels, append(slices.Clone(values), formatFloat(math.Inf(1))), float64(count))
+
+		writeSample(w, h.metricName+"_sum", h.vec.labels, values, sum)
+		writeSample(w, h.metricName+"_count", h.vec.labels, values, float64(count))
+	})
+}
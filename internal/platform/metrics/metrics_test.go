@@ -0,0 +1,93 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agkmw/reddit-clone/internal/platform/metrics"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	// Registered out of order to check that families are sorted by name.
+	latency := reg.NewHistogramVec("http_request_duration_seconds", "Request latency.", []float64{1, .1, .5}, "route")
+	requests := reg.NewCounterVec("http_requests_total", "Requests served,\nby route and status.", "route", "status")
+	reg.NewGauge("db_connections", `Open connections in C:\pool.`).Set(3)
+
+	requests.With("/v1/users", "200").Add(2)
+	requests.With(`/v1/"quoted"`, "404").Inc()
+	requests.With("/a\\b\nc", "500").Inc()
+
+	users := latency.With("/v1/users")
+	for _, v := range []float64{.05, .1, .3, .5, .7, 2} {
+		users.Observe(v)
+	}
+	latency.With("/v1/posts").Observe(.2)
+
+	var b strings.Builder
+	if err := reg.Write(&b); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	const want = `# HELP db_connections Open connections in C:\\pool.
+# TYPE db_connections gauge
+db_connections 3
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/v1/posts",le="0.1"} 0
+http_request_duration_seconds_bucket{route="/v1/posts",le="0.5"} 1
+http_request_duration_seconds_bucket{route="/v1/posts",le="1"} 1
+http_request_duration_seconds_bucket{route="/v1/posts",le="+Inf"} 1
+http_request_duration_seconds_sum{route="/v1/posts"} 0.2
+http_request_duration_seconds_count{route="/v1/posts"} 1
+http_request_duration_seconds_bucket{route="/v1/users",le="0.1"} 2
+http_request_duration_seconds_bucket{route="/v1/users",le="0.5"} 4
+http_request_duration_seconds_bucket{route="/v1/users",le="1"} 5
+http_request_duration_seconds_bucket{route="/v1/users",le="+Inf"} 6
+http_request_duration_seconds_sum{route="/v1/users"} 3.65
+http_request_duration_seconds_count{route="/v1/users"} 6
+# HELP http_requests_total Requests served,\nby route and status.
+# TYPE http_requests_total counter
+http_requests_total{route="/a\\b\nc",status="500"} 1
+http_requests_total{route="/v1/\"quoted\"",status="404"} 1
+http_requests_total{route="/v1/users",status="200"} 2
+`
+
+	if got := b.String(); got != want {
+		t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramBucketBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		want  string
+	}{
+		{name: "below the first bound", value: .05, want: `le="0.1"} 1`},
+		{name: "on the first bound", value: .1, want: `le="0.1"} 1`},
+		{name: "on a middle bound", value: .5, want: `le="0.5"} 1`},
+		{name: "on the last bound", value: 1, want: `le="1"} 1`},
+		{name: "above the last bound", value: 1.5, want: `le="1"} 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := metrics.NewRegistry()
+			reg.NewHistogramVec("h", "h", []float64{.1, .5, 1}).With().Observe(tt.value)
+
+			var b strings.Builder
+			if err := reg.Write(&b); err != nil {
+				t.Fatalf("Write: %s", err)
+			}
+
+			if !strings.Contains(b.String(), tt.want) {
+				t.Errorf("no %s in\n%s", tt.want, b.String())
+			}
+			if !strings.Contains(b.String(), `h_bucket{le="+Inf"} 1`) {
+				t.Errorf("value missing from +Inf bucket\n%s", b.String())
+			}
+		})
+	}
+}
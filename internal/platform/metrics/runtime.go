@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// RegisterRuntime exposes the Go runtime statistics. MemStats are read at most
+// once per scrape interval to keep the stop-the-world cost bounded.
+func RegisterRuntime(r *Registry) {
+	var (
+		mu   sync.Mutex
+		ms   runtime.MemStats
+		last time.Time
+	)
+
+	read := func(fn func(ms *runtime.MemStats) float64) func() float64 {
+		return func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if time.Since(last) > time.Second {
+				runtime.ReadMemStats(&ms)
+				last = time.Now()
+			}
+
+			return fn(&ms)
+		}
+	}
+
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	r.NewGaugeFunc("go_sched_gomaxprocs_threads", "The current GOMAXPROCS setting.", func() float64 {
+		return float64(runtime.GOMAXPROCS(0))
+	})
+	r.NewGaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", read(func(ms *runtime.MemStats) float64 {
+		return float64(ms.Alloc)
+	}))
+	r.NewGaugeFunc("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", read(func(ms *runtime.MemStats) float64 {
+		return float64(ms.HeapInuse)
+	}))
+	r.NewGaugeFunc("go_memstats_heap_objects", "Number of allocated objects.", read(func(ms *runtime.MemStats) float64 {
+		return float64(ms.HeapObjects)
+	}))
+	r.NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from the system.", read(func(ms *runtime.MemStats) float64 {
+		return float64(ms.Sys)
+	}))
+	r.NewCounterFunc("go_memstats_mallocs_total", "Total number of mallocs.", read(func(ms *runtime.MemStats) float64 {
+		return float64(ms.Mallocs)
+	}))
+	r.NewCounterFunc("go_gc_cycles_total", "Number of completed GC cycles.", read(func(ms *runtime.MemStats) float64 {
+		return float64(ms.NumGC)
+	}))
+	r.NewCounterFunc("go_gc_pause_seconds_total", "Total GC stop-the-world pause time in seconds.", read(func(ms *runtime.MemStats) float64 {
+		return time.Duration(ms.PauseTotalNs).Seconds()
+	}))
+}
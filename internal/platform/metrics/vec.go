@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// vec keeps one series per distinct set of label values.
+type vec[T any] struct {
+	labels []string
+	newFn  func() T
+
+	mu     sync.RWMutex
+	series map[string]*entry[T]
+}
+
+type entry[T any] struct {
+	values []string
+	metric T
+}
+
+func newVec[T any](labels []string, newFn func() T) *vec[T] {
+	return &vec[T]{
+		labels: labels,
+		newFn:  newFn,
+		series: make(map[string]*entry[T]),
+	}
+}
+
+func (v *vec[T]) with(values ...string) T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: expected %d label values, got %d", len(v.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	e, ok := v.series[key]
+	v.mu.RUnlock()
+
+	if ok {
+		return e.metric
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if e, ok := v.series[key]; ok {
+		return e.metric
+	}
+
+	e = &entry[T]{
+		values: slices.Clone(values),
+		metric: v.newFn(),
+	}
+	v.series[key] = e
+
+	return e.metric
+}
+
+func (v *vec[T]) each(fn func(values []string, metric T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	v.mu.RUnlock()
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		v.mu.RLock()
+		e := v.series[k]
+		v.mu.RUnlock()
+
+		fn(e.values, e.metric)
+	}
+}
@@ -2,6 +2,8 @@ package mid
 
 import (
 	"context"
+	"maps"
+	"slices"
 
 	"github.com/agkmw/reddit-clone/internal/platform/errs"
 	"github.com/agkmw/reddit-clone/internal/platform/logger"
@@ -17,11 +19,33 @@ func Errors(
 		return nil
 	}
 
-	log.Error(ctx, "request failed", "error", "error", err)
+	e, ok := errs.Get(err)
+	if !ok {
+		e = errs.New(errs.Unknown, err, nil)
+	}
+
+	file, fn := e.Location()
+
+	args := []any{
+		"error", e.Unwrap(),
+		"type", e.Type().String(),
+		"source", file,
+		"func", fn,
+	}
+
+	fields := e.Fields()
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		args = append(args, k, fields[k])
+	}
 
-	if e, ok := errs.Get(err); ok {
-		return e
+	// Client mistakes are logged below error level so that they do not
+	// raise alerts; only failures of the server itself should page anyone.
+	switch e.Type() {
+	case errs.Internal, errs.Unknown:
+		log.Error(ctx, "request failed", args...)
+	default:
+		log.Warn(ctx, "request failed", args...)
 	}
 
-	return errs.New(errs.Unknown, err, nil)
+	return e
 }
@@ -0,0 +1,79 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+	"github.com/agkmw/reddit-clone/internal/platform/logger"
+	"github.com/agkmw/reddit-clone/internal/platform/mid"
+)
+
+func TestErrorsLevel(t *testing.T) {
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		err       error
+		wantLevel logger.Level
+	}{
+		{
+			name:      "not found",
+			err:       errs.NewClientError(errs.NotFound, cause, i18n.Message{Key: "user.not_found"}),
+			wantLevel: logger.LevelWarn,
+		},
+		{
+			name:      "conflict",
+			err:       errs.NewClientError(errs.AlreadyExists, cause, i18n.Message{Key: "user.username_taken"}),
+			wantLevel: logger.LevelWarn,
+		},
+		{
+			name:      "failed validation",
+			err:       errs.New(errs.FailedValidation, cause, errs.ErrorInfo{"name": "must be provided"}),
+			wantLevel: logger.LevelWarn,
+		},
+		{
+			name:      "internal",
+			err:       errs.NewServerError(errs.Internal, cause),
+			wantLevel: logger.LevelError,
+		},
+		{
+			name:      "wrapped",
+			err:       errs.Wrap(cause, "get user", nil),
+			wantLevel: logger.LevelError,
+		},
+		{
+			name:      "plain error",
+			err:       cause,
+			wantLevel: logger.LevelError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var levels []logger.Level
+			record := func(ctx context.Context, r logger.Record) {
+				levels = append(levels, r.Level)
+			}
+
+			log := logger.NewWithEvents(io.Discard, logger.LevelDebug, "test",
+				func(context.Context) string { return "" },
+				logger.Events{Warn: record, Error: record},
+			)
+
+			err := mid.Errors(context.Background(), log, func(ctx context.Context) error {
+				return tt.err
+			})
+			if err == nil {
+				t.Fatal("Errors returned nil")
+			}
+
+			if len(levels) != 1 || levels[0] != tt.wantLevel {
+				t.Errorf("logged at %v, want %v", levels, tt.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+const memoryShards = 64
+
+// Memory is an in-process Limiter. Keys are spread over shards so that busy
+// keys do not contend on one lock. Each replica keeps its own state, so use
+// a shared store when running more than one.
+type Memory struct {
+	seed   maphash.Seed
+	shards [memoryShards]memoryShard
+}
+
+type memoryShard struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+}
+
+func NewMemory() *Memory {
+	m := Memory{
+		seed: maphash.MakeSeed(),
+	}
+
+	for i := range m.shards {
+		m.shards[i].tats = make(map[string]time.Time)
+	}
+
+	return &m
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tat, res := limit.Check(s.tats[key], time.Now())
+	if res.Allowed {
+		s.tats[key] = tat
+	}
+
+	return res, nil
+}
+
+// Len returns the number of keys currently tracked.
+func (m *Memory) Len() int {
+	var n int
+
+	for i := range m.shards {
+		s := &m.shards[i]
+
+		s.mu.Lock()
+		n += len(s.tats)
+		s.mu.Unlock()
+	}
+
+	return n
+}
+
+// Run evicts keys whose TAT has passed every minute until ctx is canceled.
+// Such a key has its full burst back, so forgetting it changes nothing.
+func (m *Memory) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			m.evict(time.Now())
+		}
+	}
+}
+
+func (m *Memory) evict(now time.Time) {
+	for i := range m.shards {
+		s := &m.shards[i]
+
+		s.mu.Lock()
+		for key, tat := range s.tats {
+			if !tat.After(now) {
+				delete(s.tats, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (m *Memory) shard(key string) *memoryShard {
+	return &m.shards[maphash.String(m.seed, key)%memoryShards]
+}
@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tier is how much a user is trusted, which decides which limits apply.
+type Tier string
+
+const (
+	TierAnonymous   Tier = "anonymous"
+	TierNew         Tier = "new"
+	TierEstablished Tier = "established"
+	TierModerator   Tier = "moderator"
+)
+
+func ParseTier(s string) (Tier, error) {
+	switch t := Tier(strings.ToLower(strings.TrimSpace(s))); t {
+	case TierAnonymous, TierNew, TierEstablished, TierModerator:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unknown tier %q", s)
+	}
+}
+
+// Policy holds the limit of a route per tier. A tier without an entry is
+// not limited by the route.
+type Policy map[Tier]Limit
+
+// Table holds route policies keyed by method and route pattern, e.g.
+// "POST /v1/users".
+type Table map[string]Policy
+
+// ParseTable parses a table written with ParseLimit strings, e.g.
+//
+//	{"POST /v1/posts": {"new": "5 posts/10min", "established": "30 posts/10min"}}
+func ParseTable(raw map[string]map[string]string) (Table, error) {
+	table := make(Table, len(raw))
+
+	for route, tiers := range raw {
+		method, pattern, ok := strings.Cut(route, " ")
+		if !ok || method == "" || !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("route %q: want \"METHOD /pattern\"", route)
+		}
+
+		policy := make(Policy, len(tiers))
+
+		for name, s := range tiers {
+			tier, err := ParseTier(name)
+			if err != nil {
+				return nil, fmt.Errorf("route %q: %w", route, err)
+			}
+
+			limit, err := ParseLimit(s)
+			if err != nil {
+				return nil, fmt.Errorf("route %q, tier %s: %w", route, tier, err)
+			}
+
+			policy[tier] = limit
+		}
+
+		table[strings.ToUpper(method)+" "+pattern] = policy
+	}
+
+	return table, nil
+}
+
+// ParseLimit parses limits such as "5 posts/10min", "100/1h" or "3 per
+// day". The word after the count only documents what is being counted.
+func ParseLimit(s string) (Limit, error) {
+	count, window, ok := strings.Cut(s, "/")
+	if !ok {
+		count, window, ok = strings.Cut(s, " per ")
+	}
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q: want \"<count> [things]/<window>\"", s)
+	}
+
+	fields := strings.Fields(count)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Limit{}, fmt.Errorf("limit %q: want \"<count> [things]/<window>\"", s)
+	}
+
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("limit %q: count must be a positive integer", s)
+	}
+
+	d, err := parseWindow(window)
+	if err != nil {
+		return Limit{}, fmt.Errorf("limit %q: %w", s, err)
+	}
+
+	return Limit{Requests: n, Window: d}, nil
+}
+
+// String formats l the way ParseLimit reads it.
+func (l Limit) String() string {
+	s := fmt.Sprintf("%d/%s", l.Requests, l.Window)
+	if l.Burst > 0 && l.Burst != l.Requests {
+		s += fmt.Sprintf(" (burst %d)", l.Burst)
+	}
+
+	return s
+}
+
+var windowUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+}
+
+// parseWindow accepts "10min", "10 minutes", "hour" and Go durations.
+func parseWindow(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		return 0, fmt.Errorf("window %q has no unit", s)
+	}
+
+	n := 1
+	if i > 0 {
+		n, _ = strconv.Atoi(s[:i])
+	}
+
+	unit, ok := windowUnits[strings.TrimSpace(s[i:])]
+	if !ok || n < 1 {
+		return 0, fmt.Errorf("unknown window %q", s)
+	}
+
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,107 @@
+// Package ratelimit implements the generic cell rate algorithm (GCRA) over
+// pluggable stores. GCRA behaves like a token bucket but keeps a single
+// timestamp per key, the theoretical arrival time (TAT), which makes it cheap
+// to keep in memory and easy to update atomically in a database.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Requests per Window on average, with bursts of up to Burst
+// requests. Burst defaults to Requests.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+// PerSecond converts a requests per second rate and burst to a Limit.
+func PerSecond(rps float64, burst int) Limit {
+	return Limit{
+		Requests: 1,
+		Window:   time.Duration(float64(time.Second) / rps),
+		Burst:    burst,
+	}
+}
+
+// Interval is the time it takes to earn back one request.
+func (l Limit) Interval() time.Duration {
+	if l.Requests <= 0 {
+		return l.Window
+	}
+
+	return l.Window / time.Duration(l.Requests)
+}
+
+// Size is the most requests that can be made at once.
+func (l Limit) Size() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return max(l.Requests, 1)
+}
+
+// Tolerance is how far the TAT may run ahead of now before requests are
+// rejected.
+func (l Limit) Tolerance() time.Duration {
+	return time.Duration(l.Size()) * l.Interval()
+}
+
+// Check decides a request made at now against the stored TAT, which is the
+// zero time for a key that has not been seen. It returns the TAT to store
+// when the request is allowed.
+func (l Limit) Check(tat, now time.Time) (time.Time, Result) {
+	interval := l.Interval()
+
+	if tat.Before(now) {
+		tat = now
+	}
+
+	next := tat.Add(interval)
+
+	res := Result{
+		Limit: l.Size(),
+	}
+
+	if allowAt := next.Add(-l.Tolerance()); now.Before(allowAt) {
+		res.RetryAfter = allowAt.Sub(now)
+		res.Reset = tat.Sub(now)
+		return tat, res
+	}
+
+	res.Allowed = true
+	res.Reset = next.Sub(now)
+
+	if interval > 0 {
+		res.Remaining = int(now.Add(l.Tolerance()).Sub(next) / interval)
+	}
+
+	return next, res
+}
+
+// Result describes the decision for one request.
+type Result struct {
+	Allowed bool
+
+	// Limit is the burst size, which is what a client can spend at once.
+	Limit int
+
+	// Remaining is how many more requests would be allowed right now.
+	Remaining int
+
+	// Reset is how long until the full burst is available again.
+	Reset time.Duration
+
+	// RetryAfter is how long to wait before retrying a rejected request.
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether the request identified by key is within limit.
+// Implementations must be safe for concurrent use. Different limits must
+// not share a key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
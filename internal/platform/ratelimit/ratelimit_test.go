@@ -0,0 +1,161 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
+)
+
+func TestLimitCheck(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tenPer10s := ratelimit.Limit{Requests: 10, Window: 10 * time.Second}
+	burst3 := ratelimit.Limit{Requests: 10, Window: 10 * time.Second, Burst: 3}
+
+	tests := []struct {
+		name  string
+		limit ratelimit.Limit
+
+		// tat is the stored TAT as an offset from now; nil means the key
+		// has not been seen.
+		tat *time.Duration
+
+		want    ratelimit.Result
+		wantTAT time.Duration
+	}{
+		{
+			name:    "first request",
+			limit:   tenPer10s,
+			want:    ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: time.Second},
+			wantTAT: time.Second,
+		},
+		{
+			name:    "stale TAT counts from now",
+			limit:   tenPer10s,
+			tat:     offset(-time.Hour),
+			want:    ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: time.Second},
+			wantTAT: time.Second,
+		},
+		{
+			name:    "TAT equal to now",
+			limit:   tenPer10s,
+			tat:     offset(0),
+			want:    ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: time.Second},
+			wantTAT: time.Second,
+		},
+		{
+			name:    "last request of the burst",
+			limit:   tenPer10s,
+			tat:     offset(9 * time.Second),
+			want:    ratelimit.Result{Allowed: true, Limit: 10, Remaining: 0, Reset: 10 * time.Second},
+			wantTAT: 10 * time.Second,
+		},
+		{
+			name:    "just past the last request",
+			limit:   tenPer10s,
+			tat:     offset(9*time.Second + time.Nanosecond),
+			want:    ratelimit.Result{Limit: 10, Reset: 9*time.Second + time.Nanosecond, RetryAfter: time.Nanosecond},
+			wantTAT: 9*time.Second + time.Nanosecond,
+		},
+		{
+			name:    "burst spent",
+			limit:   tenPer10s,
+			tat:     offset(10 * time.Second),
+			want:    ratelimit.Result{Limit: 10, Reset: 10 * time.Second, RetryAfter: time.Second},
+			wantTAT: 10 * time.Second,
+		},
+		{
+			name:    "far over the limit",
+			limit:   tenPer10s,
+			tat:     offset(15 * time.Second),
+			want:    ratelimit.Result{Limit: 10, Reset: 15 * time.Second, RetryAfter: 6 * time.Second},
+			wantTAT: 15 * time.Second,
+		},
+		{
+			name:    "burst below requests, first request",
+			limit:   burst3,
+			want:    ratelimit.Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+			wantTAT: time.Second,
+		},
+		{
+			name:    "burst below requests, last request",
+			limit:   burst3,
+			tat:     offset(2 * time.Second),
+			want:    ratelimit.Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+			wantTAT: 3 * time.Second,
+		},
+		{
+			name:    "burst below requests, spent",
+			limit:   burst3,
+			tat:     offset(3 * time.Second),
+			want:    ratelimit.Result{Limit: 3, Reset: 3 * time.Second, RetryAfter: time.Second},
+			wantTAT: 3 * time.Second,
+		},
+		{
+			name:    "per second rate, last request",
+			limit:   ratelimit.PerSecond(2, 5),
+			tat:     offset(2 * time.Second),
+			want:    ratelimit.Result{Allowed: true, Limit: 5, Remaining: 0, Reset: 2500 * time.Millisecond},
+			wantTAT: 2500 * time.Millisecond,
+		},
+		{
+			name:    "per second rate, spent",
+			limit:   ratelimit.PerSecond(2, 5),
+			tat:     offset(2500 * time.Millisecond),
+			want:    ratelimit.Result{Limit: 5, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond},
+			wantTAT: 2500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tat time.Time
+			if tt.tat != nil {
+				tat = now.Add(*tt.tat)
+			}
+
+			gotTAT, got := tt.limit.Check(tat, now)
+
+			if got != tt.want {
+				t.Errorf("Check result = %+v, want %+v", got, tt.want)
+			}
+			if want := now.Add(tt.wantTAT); !gotTAT.Equal(want) {
+				t.Errorf("Check TAT = now%+v, want now%+v", gotTAT.Sub(now), tt.wantTAT)
+			}
+		})
+	}
+}
+
+func TestLimitCheckRecovers(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 3, Window: 3 * time.Second}
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	var tat time.Time
+	for i := range 3 {
+		var res ratelimit.Result
+		tat, res = limit.Check(tat, now)
+		if !res.Allowed {
+			t.Fatalf("request %d rejected", i+1)
+		}
+	}
+
+	if _, res := limit.Check(tat, now); res.Allowed {
+		t.Fatal("request over the burst allowed")
+	}
+
+	// One interval later exactly one request has been earned back.
+	now = now.Add(limit.Interval())
+
+	tat, res := limit.Check(tat, now)
+	if !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after one interval: %+v, want allowed with none remaining", res)
+	}
+	if _, res := limit.Check(tat, now); res.Allowed {
+		t.Fatal("second request after one interval allowed")
+	}
+}
+
+func offset(d time.Duration) *time.Duration {
+	return &d
+}
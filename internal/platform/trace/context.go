@@ -0,0 +1,198 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+
+	flagSampled byte = 0x01
+)
+
+var ErrInvalidTraceParent = errors.New("invalid traceparent header")
+
+type TraceID [16]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+type SpanID [8]byte
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// SpanContext is the part of a span that crosses process boundaries, as
+// defined by W3C Trace Context.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	State   string
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&flagSampled != 0
+}
+
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceParent parses a traceparent header. Versions newer than 00 are
+// accepted as long as they start with a valid version 00 prefix, as required
+// by the specification.
+func ParseTraceParent(s string) (SpanContext, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 55 {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	version, err := hex.DecodeString(s[0:2])
+	if err != nil || version[0] == 0xff {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	if version[0] == 0 && len(s) != 55 {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	if len(s) > 55 && s[55] != '-' {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var sc SpanContext
+
+	if err := decodeLowerHex(sc.TraceID[:], s[3:35]); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	if err := decodeLowerHex(sc.SpanID[:], s[36:52]); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var flags [1]byte
+	if err := decodeLowerHex(flags[:], s[53:55]); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	sc.Flags = flags[0]
+
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	return sc, nil
+}
+
+func decodeLowerHex(dst []byte, s string) error {
+	if strings.ToLower(s) != s {
+		return ErrInvalidTraceParent
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// Extract reads the remote span context from the request headers. The
+// returned context is unchanged when there is no valid traceparent.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, err := ParseTraceParent(h.Get(headerTraceParent))
+	if err != nil {
+		return ctx
+	}
+
+	sc.State = strings.Join(h.Values(headerTraceState), ",")
+
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Inject writes the current span context into the headers of an outgoing
+// request or a response.
+func Inject(ctx context.Context, h http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	h.Set(headerTraceParent, sc.TraceParent())
+
+	if sc.State != "" {
+		h.Set(headerTraceState, sc.State)
+	}
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const (
+	spanKey ctxKey = iota + 1
+	remoteKey
+)
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// SpanContextFromContext returns the span context of the active span, falling
+// back to the remote parent extracted from the request.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+
+	sc, _ := ctx.Value(remoteKey).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
@@ -0,0 +1,317 @@
+package trace_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/trace"
+)
+
+// collector records OTLP/HTTP export requests.
+type collector struct {
+	*httptest.Server
+
+	status int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []otlpRequest
+}
+
+func newCollector(t *testing.T, status int) *collector {
+	c := collector{status: status}
+
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading export body: %s", err)
+		}
+
+		var req otlpRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decoding export body: %s", err)
+		}
+
+		c.mu.Lock()
+		c.requests = append(c.requests, r)
+		c.bodies = append(c.bodies, req)
+		c.mu.Unlock()
+
+		w.WriteHeader(c.status)
+	}))
+	t.Cleanup(c.Close)
+
+	return &c
+}
+
+func (c *collector) spans() []otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var spans []otlpSpan
+	for _, body := range c.bodies {
+		for _, rs := range body.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+
+	return spans
+}
+
+// The OTLP/HTTP JSON encoding, as far as the tests look at it.
+type otlpRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId"`
+	TraceState        string         `json:"traceState"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+func TestOTLPExport(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	span := trace.SpanData{
+		Name: "GET /v1/users/{username}",
+		Kind: trace.SpanKindServer,
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			State:   "vendor=1",
+		},
+		Parent: trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		Start:  start,
+		End:    start.Add(1500 * time.Microsecond),
+		Attributes: map[string]any{
+			"http.status_code": 404,
+			"http.route":       "/v1/users/{username}",
+			"cache.hit":        false,
+			"db.rows":          int64(3),
+			"ratio":            0.5,
+			"duration":         time.Second,
+		},
+		Status:        trace.StatusError,
+		StatusMessage: "user not found",
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		path    string
+		wantErr bool
+	}{
+		{name: "collector base URL", status: http.StatusOK, path: ""},
+		{name: "trailing slash", status: http.StatusOK, path: "/"},
+		{name: "full traces path", status: http.StatusOK, path: "/v1/traces"},
+		{name: "accepted", status: http.StatusAccepted, path: ""},
+		{name: "rejected", status: http.StatusBadRequest, path: "", wantErr: true},
+		{name: "collector failing", status: http.StatusServiceUnavailable, path: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCollector(t, tt.status)
+
+			exp := trace.NewOTLPExporter(c.URL+tt.path, "api")
+
+			err := exp.Export(context.Background(), []trace.SpanData{span})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if len(c.requests) != 1 {
+				t.Fatalf("collector got %d requests, want 1", len(c.requests))
+			}
+
+			r := c.requests[0]
+			if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+				t.Errorf("request = %s %s, want POST /v1/traces", r.Method, r.URL.Path)
+			}
+			if got := r.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			body := c.bodies[0]
+			if len(body.ResourceSpans) != 1 {
+				t.Fatalf("got %d resource spans, want 1", len(body.ResourceSpans))
+			}
+
+			res := body.ResourceSpans[0].Resource.Attributes
+			if len(res) != 1 || res[0].Key != "service.name" || res[0].Value["stringValue"] != "api" {
+				t.Errorf("resource attributes = %+v, want service.name=api", res)
+			}
+
+			spans := c.spans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+
+			got := spans[0]
+			checks := []struct {
+				field     string
+				got, want any
+			}{
+				{"traceId", got.TraceID, "4bf92f3577b34da6a3ce929d0e0e4736"},
+				{"spanId", got.SpanID, "00f067aa0ba902b7"},
+				{"parentSpanId", got.ParentSpanID, "0102030405060708"},
+				{"traceState", got.TraceState, "vendor=1"},
+				{"name", got.Name, "GET /v1/users/{username}"},
+				{"kind", got.Kind, 2},
+				{"startTimeUnixNano", got.StartTimeUnixNano, "1704207845000000000"},
+				{"endTimeUnixNano", got.EndTimeUnixNano, "1704207845001500000"},
+				{"status.code", got.Status.Code, 2},
+				{"status.message", got.Status.Message, "user not found"},
+			}
+			for _, c := range checks {
+				if c.got != c.want {
+					t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+				}
+			}
+
+			// Attributes are sorted by key, integers are sent as strings
+			// and unknown types are formatted.
+			wantAttrs := []struct {
+				key, kind string
+				value     any
+			}{
+				{"cache.hit", "boolValue", false},
+				{"db.rows", "intValue", "3"},
+				{"duration", "stringValue", "1s"},
+				{"http.route", "stringValue", "/v1/users/{username}"},
+				{"http.status_code", "intValue", "404"},
+				{"ratio", "doubleValue", 0.5},
+			}
+			if len(got.Attributes) != len(wantAttrs) {
+				t.Fatalf("attributes = %+v, want %d", got.Attributes, len(wantAttrs))
+			}
+			for i, want := range wantAttrs {
+				kv := got.Attributes[i]
+				if kv.Key != want.key || len(kv.Value) != 1 || kv.Value[want.kind] != want.value {
+					t.Errorf("attribute %d = %s %v, want %s {%s: %v}", i, kv.Key, kv.Value, want.key, want.kind, want.value)
+				}
+			}
+		})
+	}
+}
+
+func TestOTLPExportUnreachable(t *testing.T) {
+	c := newCollector(t, http.StatusOK)
+	c.Close()
+
+	exp := trace.NewOTLPExporter(c.URL, "api")
+
+	if err := exp.Export(context.Background(), []trace.SpanData{{Name: "span"}}); err == nil {
+		t.Fatal("Export to a closed collector: expected an error")
+	}
+}
+
+func TestProviderExportsSpans(t *testing.T) {
+	c := newCollector(t, http.StatusOK)
+
+	var exportErr error
+	p := trace.NewProvider(trace.Config{
+		Exporter:      trace.NewOTLPExporter(c.URL, "api"),
+		FlushInterval: time.Hour,
+		ErrorFn:       func(err error) { exportErr = err },
+	})
+
+	h := http.Header{}
+	h.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := trace.Extract(context.Background(), h)
+
+	ctx, server := p.Start(ctx, "GET /v1/users/{username}", trace.SpanKindServer)
+	_, query := p.Start(ctx, "SELECT users", trace.SpanKindClient)
+	query.SetAttributes("db.rows", 0)
+	query.End()
+	server.SetError(errors.New("user not found"))
+	server.End()
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %s", err)
+	}
+	if exportErr != nil {
+		t.Fatalf("export failed: %s", exportErr)
+	}
+
+	if len(c.requests) != 1 {
+		t.Fatalf("collector got %d requests, want the spans in one batch", len(c.requests))
+	}
+
+	spans := c.spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	q, s := spans[0], spans[1]
+
+	if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || q.TraceID != s.TraceID {
+		t.Errorf("trace ids = %s, %s, want the remote parent's", q.TraceID, s.TraceID)
+	}
+	if s.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("server span parent = %q, want the remote span", s.ParentSpanID)
+	}
+	if q.ParentSpanID != s.SpanID {
+		t.Errorf("query span parent = %q, want the server span %q", q.ParentSpanID, s.SpanID)
+	}
+	if s.Status.Code != int(trace.StatusError) || s.Status.Message != "user not found" {
+		t.Errorf("server span status = %+v, want error", s.Status)
+	}
+}
+
+func TestProviderUnsampledParent(t *testing.T) {
+	c := newCollector(t, http.StatusOK)
+
+	p := trace.NewProvider(trace.Config{
+		Exporter: trace.NewOTLPExporter(c.URL, "api"),
+	})
+
+	h := http.Header{}
+	h.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+	_, span := p.Start(trace.Extract(context.Background(), h), "GET /", trace.SpanKindServer)
+	span.End()
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %s", err)
+	}
+
+	if len(c.requests) != 0 {
+		t.Errorf("collector got %d requests, want none for an unsampled trace", len(c.requests))
+	}
+}
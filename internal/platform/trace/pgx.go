@@ -0,0 +1,41 @@
+package trace
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryTracer creates a client span for every pgx Query, QueryRow and Exec.
+// Only the SQL text is recorded, never the arguments.
+type QueryTracer struct {
+	provider *Provider
+}
+
+func NewQueryTracer(p *Provider) *QueryTracer {
+	return &QueryTracer{provider: p}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, span := t.provider.Start(ctx, "db.query", SpanKindClient)
+
+	span.SetAttributes(
+		"db.system", "postgresql",
+		"db.statement", strings.Join(strings.Fields(data.SQL), " "),
+	)
+
+	if cfg := conn.Config(); cfg != nil {
+		span.SetAttributes("db.name", cfg.Database, "server.address", cfg.Host)
+	}
+
+	return ctx
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := SpanFromContext(ctx)
+
+	span.SetAttributes("db.rows_affected", data.CommandTag.RowsAffected())
+	span.SetError(data.Err)
+	span.End()
+}
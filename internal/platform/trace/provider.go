@@ -0,0 +1,158 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+type Config struct {
+	Exporter      Exporter
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	ErrorFn       func(err error)
+}
+
+// Provider batches ended spans and hands them to the exporter off the request
+// path. Spans are dropped rather than blocking when the queue is full.
+type Provider struct {
+	exporter Exporter
+	errorFn  func(err error)
+
+	batchSize int
+	interval  time.Duration
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan SpanData
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+func NewProvider(cfg Config) *Provider {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+
+	p := Provider{
+		exporter:  cfg.Exporter,
+		errorFn:   cfg.ErrorFn,
+		batchSize: cfg.BatchSize,
+		interval:  cfg.FlushInterval,
+		queue:     make(chan SpanData, cfg.QueueSize),
+		done:      make(chan struct{}),
+	}
+
+	if p.exporter == nil {
+		close(p.done)
+		return &p
+	}
+
+	go p.run()
+
+	return &p
+}
+
+func (p *Provider) Dropped() uint64 {
+	if p == nil {
+		return 0
+	}
+
+	return p.dropped.Load()
+}
+
+// Shutdown stops accepting spans and flushes what is queued.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Provider) export(data SpanData) {
+	if p == nil || p.exporter == nil {
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return
+	}
+
+	select {
+	case p.queue <- data:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+func (p *Provider) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, p.batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := p.exporter.Export(ctx, batch); err != nil && p.errorFn != nil {
+			p.errorFn(err)
+		}
+
+		batch = make([]SpanData, 0, p.batchSize)
+	}
+
+	for {
+		select {
+		case data, ok := <-p.queue:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, data)
+			if len(batch) >= p.batchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+type Span struct {
+	provider *Provider
+
+	mu         sync.Mutex
+	name       string
+	kind       SpanKind
+	sc         SpanContext
+	parent     SpanID
+	start      time.Time
+	end        time.Time
+	attrs      map[string]any
+	status     StatusCode
+	statusDesc string
+	ended      bool
+}
+
+// SpanData is the immutable snapshot of an ended span handed to exporters.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]any
+	Status        StatusCode
+	StatusMessage string
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.sc
+}
+
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.name = name
+}
+
+// SetAttributes takes alternating keys and values, like the logger.
+func (s *Span) SetAttributes(kv ...any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		s.attrs[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+}
+
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = StatusError
+	s.statusDesc = err.Error()
+}
+
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = code
+	s.statusDesc = msg
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.end = time.Now()
+
+	data := SpanData{
+		Name:          s.name,
+		Kind:          s.kind,
+		SpanContext:   s.sc,
+		Parent:        s.parent,
+		Start:         s.start,
+		End:           s.end,
+		Attributes:    s.attrs,
+		Status:        s.status,
+		StatusMessage: s.statusDesc,
+	}
+	s.mu.Unlock()
+
+	if s.sc.Sampled() {
+		s.provider.export(data)
+	}
+}
+
+// =============================================================================
+
+// Start creates a span that is a child of the active span or of the remote
+// parent found in ctx. A nil provider still creates spans so IDs propagate,
+// but nothing is exported.
+func (p *Provider) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	sc := SpanContext{
+		SpanID: newSpanID(),
+	}
+
+	switch {
+	case parent.IsValid():
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.State = parent.State
+
+	default:
+		sc.TraceID = newTraceID()
+		if p != nil && p.exporter != nil {
+			sc.Flags = flagSampled
+		}
+	}
+
+	span := Span{
+		provider: p,
+		name:     name,
+		kind:     kind,
+		sc:       sc,
+		parent:   parent.SpanID,
+		start:    time.Now(),
+		attrs:    make(map[string]any),
+	}
+
+	return ContextWithSpan(ctx, &span), &span
+}
@@ -0,0 +1,39 @@
+package validator
+
+import "github.com/agkmw/reddit-clone/internal/platform/i18n"
+
+func RequiredRule() i18n.Message {
+	return i18n.Message{Key: "validation.required"}
+}
+
+func MinLengthRule(n int) i18n.Message {
+	return i18n.Message{Key: "validation.min_length", Args: i18n.Args{"count": n}}
+}
+
+func MaxLengthRule(n int) i18n.Message {
+	return i18n.Message{Key: "validation.max_length", Args: i18n.Args{"count": n}}
+}
+
+func MaxBytesRule(n int) i18n.Message {
+	return i18n.Message{Key: "validation.max_bytes", Args: i18n.Args{"count": n}}
+}
+
+func BetweenRule(min, max int) i18n.Message {
+	return i18n.Message{Key: "validation.between", Args: i18n.Args{"min": min, "max": max}}
+}
+
+func EmailRule() i18n.Message {
+	return i18n.Message{Key: "validation.email"}
+}
+
+func MatchesRule() i18n.Message {
+	return i18n.Message{Key: "validation.matches"}
+}
+
+func PermittedRule() i18n.Message {
+	return i18n.Message{Key: "validation.permitted"}
+}
+
+func UniqueRule() i18n.Message {
+	return i18n.Message{Key: "validation.unique"}
+}
@@ -3,14 +3,21 @@ package validator
 import (
 	"regexp"
 	"slices"
+	"unicode/utf8"
+
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
 )
 
+var EmailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+
+// Errors holds either plain strings or i18n.Message values; the latter are
+// localized when the response is written.
 type Validator struct {
-	Errors map[string]string
+	Errors map[string]any
 }
 
 func New() *Validator {
-	return &Validator{Errors: make(map[string]string)}
+	return &Validator{Errors: make(map[string]any)}
 }
 
 func (v *Validator) AddErrors(key, message string) {
@@ -19,12 +26,24 @@ func (v *Validator) AddErrors(key, message string) {
 	}
 }
 
+func (v *Validator) AddRule(key string, rule i18n.Message) {
+	if _, exists := v.Errors[key]; !exists {
+		v.Errors[key] = rule
+	}
+}
+
 func (v *Validator) Check(ok bool, key, message string) {
 	if !ok {
 		v.AddErrors(key, message)
 	}
 }
 
+func (v *Validator) CheckRule(ok bool, key string, rule i18n.Message) {
+	if !ok {
+		v.AddRule(key, rule)
+	}
+}
+
 func (v *Validator) Valid() bool {
 	return len(v.Errors) == 0
 }
@@ -48,3 +67,17 @@ func IsPermitted[T comparable](permittedValues []T, value T) bool {
 func Matches(value string, rx *regexp.Regexp) bool {
 	return rx.MatchString(value)
 }
+
+func MinChars(value string, n int) bool {
+	return utf8.RuneCountInString(value) >= n
+}
+
+func MaxChars(value string, n int) bool {
+	return utf8.RuneCountInString(value) <= n
+}
+
+// MaxBytes checks the encoded length of value, for limits such as bcrypt's
+// that count bytes rather than characters.
+func MaxBytes(value string, n int) bool {
+	return len(value) <= n
+}
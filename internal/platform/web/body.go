@@ -0,0 +1,137 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+)
+
+var ErrUnsupportedMediaType = errors.New("unsupported Content-Type")
+
+// LimitBody caps request bodies at n bytes. An error from a handler that
+// read past the limit is turned into a 413; a body whose Content-Length is
+// over the limit fails on the first read, without any of it being read. A
+// route's LimitBody overrides the app's, whether it is smaller or larger. It
+// returns nil, setting no limit, when n is not positive.
+func LimitBody(n int64) Middleware {
+	if n <= 0 {
+		return nil
+	}
+
+	mid := func(handler Handler) Handler {
+		hdl := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			orig := r.Body
+			if b, ok := orig.(*limitedBody); ok {
+				orig = b.orig
+			}
+
+			b := limitedBody{
+				ReadCloser: http.MaxBytesReader(w, orig, n),
+				orig:       orig,
+				limit:      n,
+				declared:   r.ContentLength,
+			}
+			r.Body = &b
+
+			if err := handler(ctx, w, r); err != nil {
+				if b.exceeded && !errs.IsType(err, errs.RequestTooLarge) {
+					return tooLarge(err, n)
+				}
+
+				return err
+			}
+
+			return nil
+		}
+
+		return hdl
+	}
+
+	return mid
+}
+
+// limitedBody is a request body capped by LimitBody. Decode leaves it as it
+// is rather than applying its own default limit.
+type limitedBody struct {
+	io.ReadCloser
+
+	orig     io.ReadCloser
+	limit    int64
+	declared int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.declared > b.limit {
+		b.exceeded = true
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+
+	n, err := b.ReadCloser.Read(p)
+
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		b.exceeded = true
+	}
+
+	return n, err
+}
+
+func tooLarge(cause error, n int64) error {
+	return errs.NewClientError(errs.RequestTooLarge, cause, i18n.Message{
+		Key:  "request.too_large",
+		Args: i18n.Args{"count": n},
+	})
+}
+
+// RequireJSON refuses requests that send a body with a Content-Type other
+// than application/json or a +json type, with a 415. Requests without a
+// body, and GET, HEAD, DELETE and OPTIONS requests, are let through.
+func RequireJSON() Middleware {
+	mid := func(handler Handler) Handler {
+		hdl := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				return handler(ctx, w, r)
+			}
+
+			if r.ContentLength == 0 {
+				return handler(ctx, w, r)
+			}
+
+			if !isJSON(r.Header.Get("Content-Type")) {
+				switch r.Method {
+				case http.MethodPost:
+					w.Header().Set("Accept-Post", contentTypeJSON)
+				case http.MethodPatch:
+					w.Header().Set("Accept-Patch", contentTypeJSON)
+				}
+
+				return errs.NewClientError(errs.UnsupportedMediaType, ErrUnsupportedMediaType,
+					i18n.Message{Key: "error.unsupported_media_type"})
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return hdl
+	}
+
+	return mid
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
@@ -0,0 +1,164 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// IPResolver finds the address of the client behind any trusted proxies.
+// Forwarding headers are only believed when the request arrives from a
+// trusted proxy, since anyone else can set them to anything.
+type IPResolver struct {
+	trusted []netip.Prefix
+}
+
+// NewIPResolver trusts the given CIDRs or single addresses. With none, the
+// peer address of the connection is always the client.
+func NewIPResolver(trusted []string) (*IPResolver, error) {
+	res := IPResolver{}
+
+	for _, s := range trusted {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", s, err)
+			}
+
+			res.trusted = append(res.trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", s, err)
+		}
+
+		res.trusted = append(res.trusted, prefix.Masked())
+	}
+
+	return &res, nil
+}
+
+// Resolve returns the client address for r. It prefers Forwarded, then
+// X-Forwarded-For, then X-Real-IP, walking proxy chains from the nearest hop
+// outwards and stopping at the first address that is not trusted. The
+// result is the zero Addr when nothing usable was found.
+func (res *IPResolver) Resolve(r *http.Request) netip.Addr {
+	peer := parseHost(r.RemoteAddr)
+
+	if !peer.IsValid() || !res.isTrusted(peer) {
+		return peer
+	}
+
+	if chain := forwardedFor(r.Header.Values("Forwarded")); len(chain) > 0 {
+		return res.walk(chain, peer)
+	}
+
+	if chain := xForwardedFor(r.Header.Values("X-Forwarded-For")); len(chain) > 0 {
+		return res.walk(chain, peer)
+	}
+
+	if addr := parseHost(r.Header.Get("X-Real-IP")); addr.IsValid() {
+		return addr
+	}
+
+	return peer
+}
+
+func (res *IPResolver) walk(chain []string, peer netip.Addr) netip.Addr {
+	client := peer
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr := parseHost(chain[i])
+		if !addr.IsValid() {
+			// A hop we cannot parse, such as an obfuscated Forwarded
+			// identifier, ends the part of the chain we can vouch for.
+			return client
+		}
+
+		client = addr
+
+		if !res.isTrusted(addr) {
+			return addr
+		}
+	}
+
+	return client
+}
+
+func (res *IPResolver) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, p := range res.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// =============================================================================
+
+func xForwardedFor(values []string) []string {
+	var chain []string
+
+	for _, v := range values {
+		for hop := range strings.SplitSeq(v, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				chain = append(chain, hop)
+			}
+		}
+	}
+
+	return chain
+}
+
+// forwardedFor extracts the for= parameters of RFC 7239 Forwarded headers.
+func forwardedFor(values []string) []string {
+	var chain []string
+
+	for _, v := range values {
+		for element := range strings.SplitSeq(v, ",") {
+			for pair := range strings.SplitSeq(element, ";") {
+				name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(name, "for") {
+					continue
+				}
+
+				chain = append(chain, strings.Trim(value, `"`))
+			}
+		}
+	}
+
+	return chain
+}
+
+// parseHost accepts "ip", "ip:port", "[ipv6]" and "[ipv6]:port".
+func parseHost(s string) netip.Addr {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}
+	}
+
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+
+	return addr.Unmap()
+}
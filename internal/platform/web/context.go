@@ -2,19 +2,29 @@ package web
 
 import (
 	"context"
+	"net/netip"
 	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
 )
 
 type ctxKey string
 
-const key ctxKey = "ctxKey"
+const (
+	key        ctxKey = "ctxKey"
+	problemKey ctxKey = "problemKey"
+	localeKey  ctxKey = "localeKey"
+	encodeKey  ctxKey = "encodeKey"
+)
 
-const defaultTraceID = "00000000-0000-0000-0000-000000000000"
+const defaultTraceID = "00000000000000000000000000000000"
 
 type Tracer struct {
 	Now        time.Time
 	StatusCode int
 	TraceID    string
+	SpanID     string
+	ClientIP   netip.Addr
 }
 
 func GetTracer(ctx context.Context) *Tracer {
@@ -56,6 +66,17 @@ func GetTraceID(ctx context.Context) string {
 	return tracer.TraceID
 }
 
+// GetClientIP returns the client address resolved for the request. It is the
+// zero Addr when it could not be determined.
+func GetClientIP(ctx context.Context) netip.Addr {
+	tracer, ok := ctx.Value(key).(*Tracer)
+	if !ok {
+		return netip.Addr{}
+	}
+
+	return tracer.ClientIP
+}
+
 func setStatusCode(ctx context.Context, statusCode int) {
 	tracer, ok := ctx.Value(key).(*Tracer)
 	if !ok {
@@ -68,3 +89,50 @@ func setStatusCode(ctx context.Context, statusCode int) {
 func setTracer(ctx context.Context, tracer *Tracer) context.Context {
 	return context.WithValue(ctx, key, tracer)
 }
+
+func getProblem(ctx context.Context) (ProblemConfig, bool) {
+	cfg, ok := ctx.Value(problemKey).(ProblemConfig)
+	return cfg, ok
+}
+
+func setProblem(ctx context.Context, cfg ProblemConfig) context.Context {
+	return context.WithValue(ctx, problemKey, cfg)
+}
+
+func getNegotiation(ctx context.Context) *negotiation {
+	n, ok := ctx.Value(encodeKey).(*negotiation)
+	if !ok {
+		return &negotiation{}
+	}
+
+	return n
+}
+
+func setNegotiation(ctx context.Context, n *negotiation) context.Context {
+	return context.WithValue(ctx, encodeKey, n)
+}
+
+func GetLocalizer(ctx context.Context) *i18n.Localizer {
+	loc, ok := ctx.Value(localeKey).(*i18n.Localizer)
+	if !ok {
+		catalog := i18n.Default()
+		return catalog.Localizer(catalog.Match())
+	}
+
+	return loc
+}
+
+// SetLanguage overrides the language negotiated from Accept-Language for the
+// rest of the request, e.g. with the authenticated user's stored preference.
+func SetLanguage(ctx context.Context, lang string) {
+	loc, ok := ctx.Value(localeKey).(*i18n.Localizer)
+	if !ok {
+		return
+	}
+
+	loc.SetLanguage(lang)
+}
+
+func setLocalizer(ctx context.Context, loc *i18n.Localizer) context.Context {
+	return context.WithValue(ctx, localeKey, loc)
+}
@@ -0,0 +1,238 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	defaultCORSMethods = []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+
+	defaultCORSHeaders = []string{
+		"Accept",
+		"Accept-Language",
+		"Authorization",
+		"Content-Type",
+		"If-Match",
+		"If-None-Match",
+		"Idempotency-Key",
+		"Traceparent",
+		"Tracestate",
+	}
+
+	defaultCORSExposed = []string{
+		"Content-Language",
+		"ETag",
+		"Location",
+		"RateLimit-Limit",
+		"RateLimit-Remaining",
+		"RateLimit-Reset",
+		"Retry-After",
+		"Traceparent",
+	}
+)
+
+type CORSConfig struct {
+	// Origins lists the trusted origins, e.g. https://app.example.com. A
+	// single * in an entry matches any run of characters, as in
+	// https://*.example.com, and an entry of just * trusts every origin.
+	Origins []string
+
+	// Methods, Headers and ExposedHeaders default to what the API uses.
+	Methods        []string
+	Headers        []string
+	ExposedHeaders []string
+
+	// Credentials lets browsers send cookies and Authorization headers. It
+	// cannot be combined with an origin of *, which would let every site
+	// make requests with the user's credentials.
+	Credentials bool
+
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// CORS answers cross-origin requests from trusted origins. It runs in front
+// of routing so that preflight requests are answered for every route and
+// error responses carry the headers the browser needs to read them.
+type CORS struct {
+	any      bool
+	exact    map[string]bool
+	patterns [][2]string
+
+	methods     []string
+	headers     map[string]bool
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func NewCORS(cfg CORSConfig) (*CORS, error) {
+	c := CORS{
+		exact:       make(map[string]bool),
+		methods:     cfg.Methods,
+		headers:     make(map[string]bool),
+		credentials: cfg.Credentials,
+	}
+
+	for _, o := range cfg.Origins {
+		o = strings.TrimSpace(o)
+
+		switch n := strings.Count(o, "*"); {
+		case o == "":
+			continue
+		case o == "*" && cfg.Credentials:
+			return nil, fmt.Errorf("cors origin %q: cannot be used with credentials", o)
+		case o == "*":
+			c.any = true
+		case n == 0:
+			c.exact[strings.ToLower(o)] = true
+		case n == 1:
+			prefix, suffix, _ := strings.Cut(strings.ToLower(o), "*")
+			c.patterns = append(c.patterns, [2]string{prefix, suffix})
+		default:
+			return nil, fmt.Errorf("cors origin %q: only one * is allowed", o)
+		}
+	}
+
+	if len(c.methods) == 0 {
+		c.methods = defaultCORSMethods
+	}
+
+	headers := cfg.Headers
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	for _, h := range headers {
+		c.headers[http.CanonicalHeaderKey(h)] = true
+	}
+
+	exposed := cfg.ExposedHeaders
+	if len(exposed) == 0 {
+		exposed = defaultCORSExposed
+	}
+	c.exposed = strings.Join(exposed, ", ")
+
+	if cfg.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return &c, nil
+}
+
+// handle adds the CORS headers for r and reports whether r was a preflight
+// request that has been answered.
+func (c *CORS) handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+	h := w.Header()
+
+	// The response depends on these request headers whether or not the
+	// origin is allowed, so caches must key on them.
+	h.Add("Vary", "Origin")
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" || !c.allowed(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		return preflight
+	}
+
+	if c.any {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if c.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		h.Set("Access-Control-Expose-Headers", c.exposed)
+		return false
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	requested, ok := c.allowedHeaders(r.Header.Values("Access-Control-Request-Headers"))
+
+	if !slices.Contains(c.methods, method) || !ok {
+		// Without the allow headers the browser fails the preflight.
+		h.Del("Access-Control-Allow-Origin")
+		h.Del("Access-Control-Allow-Credentials")
+		w.WriteHeader(http.StatusNoContent)
+		return true
+	}
+
+	h.Set("Access-Control-Allow-Methods", strings.Join(c.methods, ", "))
+	if len(requested) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if c.maxAge != "" {
+		h.Set("Access-Control-Max-Age", c.maxAge)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+func (c *CORS) allowed(origin string) bool {
+	if c.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if c.exact[origin] {
+		return true
+	}
+
+	for _, p := range c.patterns {
+		if len(origin) > len(p[0])+len(p[1]) && strings.HasPrefix(origin, p[0]) && strings.HasSuffix(origin, p[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedHeaders returns the requested headers, or false if any of them is
+// not allowed.
+func (c *CORS) allowedHeaders(values []string) ([]string, bool) {
+	var requested []string
+
+	for _, v := range values {
+		for name := range strings.SplitSeq(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			if !c.headers[name] {
+				return nil, false
+			}
+
+			requested = append(requested, name)
+		}
+	}
+
+	return requested, true
+}
@@ -3,15 +3,23 @@ package web
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
 )
 
+// maxBodyBytes limits bodies on routes without a LimitBody middleware.
+const maxBodyBytes = 1024 * 1024
+
+// Decode reads a single JSON value from the body into dst. Its errors are
+// client errors whose messages come from the catalog.
 func Decode(w http.ResponseWriter, r *http.Request, dst any) error {
-	maxBytes := 1024 * 1024
-	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	if _, ok := r.Body.(*limitedBody); !ok {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	}
 
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
@@ -24,38 +32,45 @@ func Decode(w http.ResponseWriter, r *http.Request, dst any) error {
 
 		switch {
 		case errors.As(err, &syntaxError):
-			return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
+			return decodeError(err, "decode.syntax", i18n.Args{"offset": syntaxError.Offset})
 
 		case errors.Is(err, io.ErrUnexpectedEOF):
-			return fmt.Errorf("body contains badly-formed JSON")
+			return decodeError(err, "decode.malformed", nil)
 
 		case errors.As(err, &unmarshalTypeError):
 			if unmarshalTypeError.Field != "" {
-				return fmt.Errorf("body contains incorrect JSON type for field %q", unmarshalTypeError.Field)
+				return decodeError(err, "decode.field_type", i18n.Args{"field": unmarshalTypeError.Field})
 			}
-			return fmt.Errorf("body contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
+			return decodeError(err, "decode.type", i18n.Args{"offset": unmarshalTypeError.Offset})
 
 		case errors.Is(err, io.EOF):
-			return fmt.Errorf("body must not be empty")
+			return decodeError(err, "decode.empty", nil)
 
-		case strings.HasPrefix(err.Error(), "json: unknown field: "):
-			field := strings.TrimPrefix(err.Error(), "json: unknown field: ")
-			return fmt.Errorf("body contains unknown key %s", field)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+			return decodeError(err, "decode.unknown_field", i18n.Args{"field": field})
 
 		case errors.As(err, &maxBytesError):
-			return fmt.Errorf("body must not be greater than %d bytes", maxBytesError.Limit)
+			return errs.NewClientError(errs.RequestTooLarge, err, i18n.Message{
+				Key:  "request.too_large",
+				Args: i18n.Args{"count": maxBytesError.Limit},
+			})
 
 		case errors.As(err, &invalidUnmarshalError):
 			panic(err)
 
 		default:
-			return err
+			return decodeError(err, "error.bad_request", nil)
 		}
 	}
 
 	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
-		return fmt.Errorf("body must contain only a single JSON value")
+		return decodeError(errors.New("body contains more than one JSON value"), "decode.multiple_values", nil)
 	}
 
 	return nil
 }
+
+func decodeError(cause error, key string, args i18n.Args) error {
+	return errs.NewClientError(errs.InvalidArgument, cause, i18n.Message{Key: key, Args: args})
+}
@@ -1,8 +1,8 @@
 package web
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -25,7 +25,41 @@ func EncodeWithHeaders(
 	data Envelope,
 	headers http.Header,
 ) error {
-	return encode(ctx, w, status, data, http.Header{})
+	return encode(ctx, w, status, data, headers)
+}
+
+// EncodeAlternates sends data as JSON unless the client's Accept header
+// prefers one of alts, such as CSV or NDJSON.
+func EncodeAlternates(
+	ctx context.Context,
+	w http.ResponseWriter,
+	status int,
+	data Envelope,
+	alts ...Alternate,
+) error {
+	n := getNegotiation(ctx)
+
+	w.Header().Add("Vary", "Accept")
+
+	i := n.choose(alts)
+	if i < 0 {
+		return encode(ctx, w, status, data, http.Header{})
+	}
+
+	setStatusCode(ctx, status)
+
+	var buf bytes.Buffer
+	if err := alts[i].Encoder.Encode(&buf, alts[i].Data); err != nil {
+		return fmt.Errorf("web.encode.marshal: %w", err)
+	}
+
+	w.Header().Set("Content-Type", alts[i].Encoder.ContentType())
+
+	if err := write(w, n, status, buf.Bytes()); err != nil {
+		return fmt.Errorf("web.encode.write: %w", err)
+	}
+
+	return nil
 }
 
 func encode(
@@ -42,21 +76,22 @@ func encode(
 		return nil
 	}
 
-	js, err := json.MarshalIndent(data, "", "\t")
-	if err != nil {
+	n := getNegotiation(ctx)
+
+	var buf bytes.Buffer
+	if err := (jsonEncoder{pretty: n.pretty}).Encode(&buf, data); err != nil {
 		return fmt.Errorf("web.encode.marshal: %w", err)
 	}
 
-	js = append(js, '\n')
-
 	for k, v := range headers {
 		w.Header()[k] = v
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentTypeJSON)
+	}
 
-	if _, err := w.Write(js); err != nil {
+	if err := write(w, n, status, buf.Bytes()); err != nil {
 		return fmt.Errorf("web.encode.write: %w", err)
 	}
 
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+const contentTypeJSON = "application/json"
+
+// Encoder writes a response body in one media type.
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, data any) error
+}
+
+// Alternate is a representation an endpoint offers besides its JSON
+// envelope, sent when the Accept header prefers it.
+type Alternate struct {
+	Encoder Encoder
+	Data    any
+}
+
+var (
+	// NDJSON writes each element of a slice as one line of JSON.
+	NDJSON Encoder = ndjsonEncoder{}
+
+	// CSV writes a [][]string, or a value implementing CSVMarshaler, as
+	// comma separated records.
+	CSV Encoder = csvEncoder{}
+)
+
+// CSVMarshaler is implemented by values that can be written as CSV. The
+// first record is the header.
+type CSVMarshaler interface {
+	MarshalCSV() ([][]string, error)
+}
+
+// =============================================================================
+
+type jsonEncoder struct {
+	pretty bool
+}
+
+func (jsonEncoder) ContentType() string {
+	return contentTypeJSON
+}
+
+func (e jsonEncoder) Encode(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
+	if e.pretty {
+		enc.SetIndent("", "\t")
+	}
+
+	return enc.Encode(data)
+}
+
+type ndjsonEncoder struct{}
+
+func (ndjsonEncoder) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjsonEncoder) Encode(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
+
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return enc.Encode(data)
+	}
+
+	for i := range v.Len() {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (csvEncoder) Encode(w io.Writer, data any) error {
+	var records [][]string
+
+	switch d := data.(type) {
+	case [][]string:
+		records = d
+	case CSVMarshaler:
+		var err error
+		if records, err = d.MarshalCSV(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("csv: cannot encode %T", data)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,85 +5,100 @@ import (
 	"net/http"
 
 	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
 )
 
 // NOTE: Add request_id field or not?
 
 var httpStatus = map[errs.ErrorType]int{
-	errs.Aborted:            http.StatusConflict,
-	errs.AlreadyExists:      http.StatusConflict,
-	errs.EditConflict:       http.StatusConflict,
-	errs.FailedPrecondition: http.StatusPreconditionFailed,
-	errs.FailedValidation:   http.StatusUnprocessableEntity,
-	errs.NotFound:           http.StatusNotFound,
-	errs.Internal:           http.StatusInternalServerError,
-	errs.InvalidArgument:    http.StatusBadRequest,
-	errs.PermissionDenied:   http.StatusForbidden,
-	errs.TooManyRequests:    http.StatusTooManyRequests,
-	errs.Unauthenticated:    http.StatusUnauthorized,
-	errs.Unknown:            http.StatusInternalServerError,
+	errs.Aborted:              http.StatusConflict,
+	errs.AlreadyExists:        http.StatusConflict,
+	errs.EditConflict:         http.StatusConflict,
+	errs.FailedPrecondition:   http.StatusPreconditionFailed,
+	errs.FailedValidation:     http.StatusUnprocessableEntity,
+	errs.NotFound:             http.StatusNotFound,
+	errs.Internal:             http.StatusInternalServerError,
+	errs.InvalidArgument:      http.StatusBadRequest,
+	errs.PermissionDenied:     http.StatusForbidden,
+	errs.PreconditionRequired: http.StatusPreconditionRequired,
+	errs.RequestTooLarge:      http.StatusRequestEntityTooLarge,
+	errs.TooManyRequests:      http.StatusTooManyRequests,
+	errs.Unauthenticated:      http.StatusUnauthorized,
+	errs.Unknown:              http.StatusInternalServerError,
+	errs.UnsupportedMediaType: http.StatusUnsupportedMediaType,
 }
 
 func ServerErrorResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "the server encountered a problem and could not process your request"
-	return ErrorResponse(ctx, w, errs.Internal, msg)
+	msg := i18n.Message{Key: "error.internal"}
+	return errorResponse(ctx, w, errs.Internal, msg, nil)
 }
 
 func NotFoundResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "the requested resource was not found"
-	return ErrorResponse(ctx, w, errs.NotFound, msg)
+	msg := i18n.Message{Key: "error.not_found"}
+	return errorResponse(ctx, w, errs.NotFound, msg, nil)
 }
 
 func BadRequestResponse(ctx context.Context, w http.ResponseWriter, data errs.ErrorInfo) error {
-	msg := "the request body contains invalid JSON"
-	return ErrorResponseWithData(ctx, w, errs.InvalidArgument, msg, data)
+	msg := i18n.Message{Key: "error.bad_request"}
+	return errorResponse(ctx, w, errs.InvalidArgument, msg, data)
 }
 
 func FailedValidationResponse(ctx context.Context, w http.ResponseWriter, data errs.ErrorInfo) error {
-	msg := "the request failed validation"
-	return ErrorResponseWithData(ctx, w, errs.FailedValidation, msg, data)
+	msg := i18n.Message{Key: "error.failed_validation"}
+	return errorResponse(ctx, w, errs.FailedValidation, msg, data)
 }
 
 func RateLimitExceededResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "too many requests, please try again later"
-	return ErrorResponse(ctx, w, errs.TooManyRequests, msg)
+	msg := i18n.Message{Key: "error.too_many_requests"}
+	return errorResponse(ctx, w, errs.TooManyRequests, msg, nil)
 }
 
 func EditConflictResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "unable to modify the resource due to an edit conflict, please try again"
-	return ErrorResponse(ctx, w, errs.EditConflict, msg)
+	msg := i18n.Message{Key: "error.edit_conflict"}
+	return errorResponse(ctx, w, errs.EditConflict, msg, nil)
 }
 
 func AlreadyExistsResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "the resource already exists"
-	return ErrorResponse(ctx, w, errs.AlreadyExists, msg)
+	msg := i18n.Message{Key: "error.already_exists"}
+	return errorResponse(ctx, w, errs.AlreadyExists, msg, nil)
 }
 
 func InvalidCredentialsResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "invalid authentication credentials"
-	return ErrorResponse(ctx, w, errs.Unauthenticated, msg)
+	msg := i18n.Message{Key: "error.invalid_credentials"}
+	return errorResponse(ctx, w, errs.Unauthenticated, msg, nil)
 }
 
 func InvalidAuthenticationTokenResponse(ctx context.Context, w http.ResponseWriter) error {
 	w.Header().Set("WWW-Authenticate", "Bearer")
 
-	msg := "invalid or missing authentication token"
-	return ErrorResponse(ctx, w, errs.Unauthenticated, msg)
+	msg := i18n.Message{Key: "error.invalid_authentication_token"}
+	return errorResponse(ctx, w, errs.Unauthenticated, msg, nil)
 }
 
 func AuthenticationRequiredResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "authentication is required to access this resource"
-	return ErrorResponse(ctx, w, errs.Unauthenticated, msg)
+	msg := i18n.Message{Key: "error.unauthenticated"}
+	return errorResponse(ctx, w, errs.Unauthenticated, msg, nil)
 }
 
 func InactiveAccountResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "your account must be activated to access this resource"
-	return ErrorResponse(ctx, w, errs.PermissionDenied, msg)
+	msg := i18n.Message{Key: "error.inactive_account"}
+	return errorResponse(ctx, w, errs.PermissionDenied, msg, nil)
 }
 
 func NotPermittedResponse(ctx context.Context, w http.ResponseWriter) error {
-	msg := "you do not have the permissions to access this resource"
-	return ErrorResponse(ctx, w, errs.PermissionDenied, msg)
+	msg := i18n.Message{Key: "error.permission_denied"}
+	return errorResponse(ctx, w, errs.PermissionDenied, msg, nil)
+}
+
+// TypeErrorResponse responds with the generic, localized message for the
+// error type.
+func TypeErrorResponse(
+	ctx context.Context,
+	w http.ResponseWriter,
+	errType errs.ErrorType,
+	data errs.ErrorInfo,
+) error {
+	return errorResponse(ctx, w, errType, errs.DefaultMessage(errType), data)
 }
 
 func ErrorResponse(
@@ -92,7 +107,7 @@ func ErrorResponse(
 	errType errs.ErrorType,
 	message string,
 ) error {
-	return errorResponse(ctx, w, errType, message, nil)
+	return errorResponse(ctx, w, errType, i18n.Message{Fallback: message}, nil)
 }
 
 func ErrorResponseWithData(
@@ -101,6 +116,18 @@ func ErrorResponseWithData(
 	errType errs.ErrorType,
 	message string,
 	data errs.ErrorInfo,
+) error {
+	return errorResponse(ctx, w, errType, i18n.Message{Fallback: message}, data)
+}
+
+// LocalizedErrorResponse is ErrorResponseWithData with a message from the
+// catalog, rendered in the language the request asked for.
+func LocalizedErrorResponse(
+	ctx context.Context,
+	w http.ResponseWriter,
+	errType errs.ErrorType,
+	message i18n.Message,
+	data errs.ErrorInfo,
 ) error {
 	return errorResponse(ctx, w, errType, message, data)
 }
@@ -109,7 +136,7 @@ func errorResponse(
 	ctx context.Context,
 	w http.ResponseWriter,
 	errType errs.ErrorType,
-	message string,
+	message i18n.Message,
 	data errs.ErrorInfo,
 ) error {
 	status, ok := httpStatus[errType]
@@ -117,9 +144,20 @@ func errorResponse(
 		status = http.StatusInternalServerError
 	}
 
+	loc := GetLocalizer(ctx)
+	msg := loc.Localize(message)
+	data = localizeData(loc, data)
+
+	w.Header().Set("Content-Language", loc.Language().String())
+
+	if cfg, ok := getProblem(ctx); ok {
+		title := problemTitle(loc, errType.String(), status)
+		return problemResponse(ctx, w, cfg, status, errType.String(), title, msg, data)
+	}
+
 	env := Envelope{
 		"code":    errType.String(),
-		"message": message,
+		"message": msg,
 	}
 
 	if data != nil {
@@ -128,3 +166,21 @@ func errorResponse(
 
 	return Encode(ctx, w, status, env)
 }
+
+func localizeData(loc *i18n.Localizer, data errs.ErrorInfo) errs.ErrorInfo {
+	if data == nil {
+		return nil
+	}
+
+	localized := make(errs.ErrorInfo, len(data))
+	for k, v := range data {
+		if m, ok := v.(i18n.Message); ok {
+			localized[k] = loc.Localize(m)
+			continue
+		}
+
+		localized[k] = v
+	}
+
+	return localized
+}
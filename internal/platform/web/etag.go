@@ -0,0 +1,84 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+)
+
+var (
+	ErrPreconditionRequired = errors.New("missing If-Match header")
+	ErrPreconditionFailed   = errors.New("If-Match does not match the current version")
+)
+
+// ETag returns the strong entity tag for a resource version. Versions only
+// change when the resource does, so equal versions mean equal resources.
+func ETag(version int) string {
+	return `"v` + strconv.Itoa(version) + `"`
+}
+
+func SetETag(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+}
+
+// NotModified answers with 304 when the client's If-None-Match already
+// names etag, and reports whether it did. The caller stops there.
+func NotModified(ctx context.Context, w http.ResponseWriter, r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !matchETag(header, etag, true) {
+		return false
+	}
+
+	SetETag(w, etag)
+	setStatusCode(ctx, http.StatusNotModified)
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// CheckIfMatch requires r to carry an If-Match header naming etag, so that a
+// client can only change the version of the resource it has seen. It returns
+// a PreconditionRequired error when the header is missing and a
+// FailedPrecondition error when it names another version.
+func CheckIfMatch(r *http.Request, etag string) error {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return errs.New(errs.PreconditionRequired, ErrPreconditionRequired, nil)
+	}
+
+	if !matchETag(header, etag, false) {
+		return errs.New(errs.FailedPrecondition, ErrPreconditionFailed, nil)
+	}
+
+	return nil
+}
+
+// matchETag reports whether the comma separated list in header names etag.
+// If-None-Match uses the weak comparison, which ignores W/ prefixes, and
+// If-Match the strong one, which never matches a weak tag.
+func matchETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if t, ok := strings.CutPrefix(tag, "W/"); ok {
+			if !weak {
+				continue
+			}
+			tag = t
+		}
+
+		if tag == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,24 @@
+package web
+
+import (
+	"context"
+	"net/http"
+)
+
+// Gate hides a route while enabled reports false, answering as if the route
+// did not exist. Attach it with HandlerFuncWithMid.
+func Gate(enabled func(ctx context.Context) bool) Middleware {
+	mid := func(handler Handler) Handler {
+		hdl := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !enabled(ctx) {
+				return NotFound(ctx, w, r)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return hdl
+	}
+
+	return mid
+}
@@ -0,0 +1,287 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+	"github.com/agkmw/reddit-clone/internal/platform/idempotency"
+)
+
+// replayedHeaders are the response headers stored with a response and sent
+// again on replay. Per-request headers such as tracing and rate limits are
+// left out.
+var replayedHeaders = []string{
+	"Content-Language",
+	"Content-Type",
+	"ETag",
+	"Location",
+}
+
+var (
+	ErrIdempotencyKeyInvalid  = errors.New("invalid Idempotency-Key header")
+	ErrIdempotencyKeyReused   = errors.New("Idempotency-Key reused for a different request")
+	ErrIdempotencyKeyInFlight = errors.New("request with this Idempotency-Key is still in progress")
+)
+
+type IdempotencyConfig struct {
+	Store idempotency.Store
+
+	// Scope keeps the keys of different clients apart. It defaults to
+	// KeyByIP.
+	Scope KeyFunc
+
+	// TTL is how long responses are kept for replay. It defaults to 24h.
+	TTL time.Duration
+
+	// Lease is how long a request may hold a key before a retry may take it
+	// over, in case the replica running it died. It defaults to a minute.
+	Lease time.Duration
+
+	// Wait is how long a duplicate waits for the original request to finish
+	// before giving up with a 409. It defaults to ten seconds.
+	Wait time.Duration
+}
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to
+// retry. The first request runs and its response is stored; retries with the
+// same key and body get that response back with Idempotent-Replayed: true.
+// A retry with a different body is refused with a 409, and a retry that
+// arrives while the first request is running waits for its response.
+// Requests without the header run as usual. Attach it with
+// HandlerFuncWithMid.
+func Idempotency(cfg IdempotencyConfig) Middleware {
+	if cfg.Scope == nil {
+		cfg.Scope = KeyByIP
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	if cfg.Wait <= 0 {
+		cfg.Wait = 10 * time.Second
+	}
+
+	mid := func(handler Handler) Handler {
+		hdl := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" {
+				return handler(ctx, w, r)
+			}
+
+			if !validIdempotencyKey(key) {
+				return errs.NewClientError(errs.InvalidArgument, ErrIdempotencyKeyInvalid,
+					i18n.Message{Key: "idempotency.invalid_key"})
+			}
+
+			body, err := readBody(r)
+			if err != nil {
+				return err
+			}
+
+			scope := cfg.Scope(ctx, r)
+			fingerprint := idempotency.Fingerprint(r.Method, r.URL.Path, body)
+
+			rec, claimed, err := claimIdempotencyKey(ctx, cfg, scope, key, fingerprint)
+			if err != nil {
+				return fmt.Errorf("idempotency: %w", err)
+			}
+
+			switch {
+			case !claimed && rec.Fingerprint != fingerprint:
+				return errs.NewClientError(errs.Aborted, ErrIdempotencyKeyReused,
+					i18n.Message{Key: "idempotency.key_reused"})
+
+			case !claimed && !rec.Done:
+				w.Header().Set("Retry-After", "1")
+				return errs.NewClientError(errs.Aborted, ErrIdempotencyKeyInFlight,
+					i18n.Message{Key: "idempotency.in_flight"})
+
+			case !claimed:
+				return replay(ctx, w, rec)
+			}
+
+			// Store the response uncompressed, so that a replay can be
+			// encoded for whatever the retry accepts.
+			rw := responseRecorder{ResponseWriter: w}
+
+			err = handler(withoutCompression(ctx), &rw, r)
+
+			// Failures are not stored so that the client can retry them;
+			// the request's own context may be gone by now.
+			store := context.WithoutCancel(ctx)
+
+			if err != nil || rw.status == 0 || rw.status >= http.StatusInternalServerError {
+				cfg.Store.Release(store, scope, key)
+				return err
+			}
+
+			header := http.Header{}
+			for _, name := range replayedHeaders {
+				if v := rw.header.Values(name); len(v) > 0 {
+					header[http.CanonicalHeaderKey(name)] = v
+				}
+			}
+
+			rec = idempotency.Record{
+				Fingerprint: fingerprint,
+				Done:        true,
+				Status:      rw.status,
+				Header:      header,
+				Body:        rw.body.Bytes(),
+			}
+
+			if err := cfg.Store.Complete(store, scope, key, rec); err != nil {
+				// The response has been sent; a retry will run again.
+				cfg.Store.Release(store, scope, key)
+				return fmt.Errorf("idempotency: storing response: %w", err)
+			}
+
+			return nil
+		}
+
+		return hdl
+	}
+
+	return mid
+}
+
+// claimIdempotencyKey claims the key, waiting while another request holds
+// it, until it is claimed, finished or cfg.Wait runs out.
+func claimIdempotencyKey(
+	ctx context.Context,
+	cfg IdempotencyConfig,
+	scope, key, fingerprint string,
+) (idempotency.Record, bool, error) {
+	deadline := time.Now().Add(cfg.Wait)
+	delay := 50 * time.Millisecond
+
+	for {
+		rec, claimed, err := cfg.Store.Claim(ctx, scope, key, fingerprint, cfg.Lease, cfg.TTL)
+		if err != nil || claimed || rec.Done || rec.Fingerprint != fingerprint {
+			return rec, claimed, err
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return rec, false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return rec, false, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = min(2*delay, time.Second)
+	}
+}
+
+// readBody reads the body so it can be fingerprinted and puts it back for
+// the handler. A body capped by LimitBody keeps its limit; any other body is
+// read up to the decoder's limit, past which Decode rejects it anyway.
+func readBody(r *http.Request) ([]byte, error) {
+	b, limited := r.Body.(*limitedBody)
+
+	var body []byte
+	var err error
+
+	if limited {
+		body, err = io.ReadAll(b)
+	} else {
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	}
+
+	if err != nil {
+		if limited && b.exceeded {
+			return nil, err
+		}
+
+		return nil, errs.NewClientError(errs.InvalidArgument, err, i18n.Message{Key: "request.unreadable_body"})
+	}
+
+	if limited {
+		b.ReadCloser = io.NopCloser(bytes.NewReader(body))
+	} else {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return body, nil
+}
+
+func replay(ctx context.Context, w http.ResponseWriter, rec idempotency.Record) error {
+	for name, values := range rec.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+
+	setStatusCode(ctx, rec.Status)
+
+	if err := write(w, getNegotiation(ctx), rec.Status, rec.Body); err != nil {
+		return fmt.Errorf("web.idempotency.replay: %w", err)
+	}
+
+	return nil
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > 255 {
+		return false
+	}
+
+	for i := range len(key) {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func withoutCompression(ctx context.Context) context.Context {
+	n := *getNegotiation(ctx)
+	n.encoding = ""
+
+	return setNegotiation(ctx, &n)
+}
+
+// =============================================================================
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+		rw.header = rw.ResponseWriter.Header().Clone()
+	}
+
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	rw.body.Write(b)
+
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
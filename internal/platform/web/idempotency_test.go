@@ -0,0 +1,328 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/idempotency"
+	"github.com/agkmw/reddit-clone/internal/platform/web"
+)
+
+// memoryStore is an idempotency.Store for a single process.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]idempotency.Record)}
+}
+
+func (s *memoryStore) Claim(ctx context.Context, scope, key, fingerprint string, lease, ttl time.Duration) (idempotency.Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[scope+"\x00"+key]; ok {
+		return rec, false, nil
+	}
+
+	rec := idempotency.Record{Fingerprint: fingerprint}
+	s.records[scope+"\x00"+key] = rec
+
+	return rec, true, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, scope, key string, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[scope+"\x00"+key] = rec
+
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, scope+"\x00"+key)
+
+	return nil
+}
+
+// handleErrors turns client errors into responses the way the API's error
+// middleware does.
+func handleErrors(handler web.Handler) web.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		err := handler(ctx, w, r)
+		if err == nil {
+			return nil
+		}
+
+		if e, ok := errs.Get(err); ok {
+			if msg, ok := e.ClientMessage(); ok {
+				return web.LocalizedErrorResponse(ctx, w, e.Type(), msg, e.Data())
+			}
+		}
+
+		return web.ServerErrorResponse(ctx, w)
+	}
+}
+
+type idempotencyTest struct {
+	app   *web.App
+	calls atomic.Int32
+
+	// fail makes the handler return an error instead of a response.
+	fail atomic.Bool
+
+	// block, when set, holds the handler until it is closed.
+	block   chan struct{}
+	started chan struct{}
+}
+
+func newIdempotencyTest(t *testing.T, wait time.Duration) *idempotencyTest {
+	it := idempotencyTest{started: make(chan struct{}, 10)}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		n := it.calls.Add(1)
+		it.started <- struct{}{}
+
+		if it.block != nil {
+			<-it.block
+		}
+
+		if it.fail.Load() {
+			return errs.NewServerError(errs.Internal, context.DeadlineExceeded)
+		}
+
+		h := http.Header{}
+		h.Set("Location", "/v1/things/1")
+		h.Set("X-Request-Only", "yes")
+
+		return web.EncodeWithHeaders(ctx, w, http.StatusCreated, web.Envelope{"call": n}, h)
+	}
+
+	it.app = web.NewApp(func(context.Context, string, ...any) {}, handleErrors)
+	it.app.HandlerFuncWithMid(http.MethodPost, "", "/things", handler, web.Idempotency(web.IdempotencyConfig{
+		Store: newMemoryStore(),
+		Wait:  wait,
+	}))
+
+	return &it
+}
+
+func (it *idempotencyTest) post(key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		r.Header.Set("Idempotency-Key", key)
+	}
+
+	w := httptest.NewRecorder()
+	it.app.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	type step struct {
+		key, body string
+
+		wantStatus   int
+		wantCall     string
+		wantReplayed bool
+	}
+
+	tests := []struct {
+		name      string
+		failFirst bool
+		steps     []step
+		wantCalls int32
+	}{
+		{
+			name: "no key runs every time",
+			steps: []step{
+				{body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":1`},
+				{body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":2`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "retry is replayed",
+			steps: []step{
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":1`},
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":1`, wantReplayed: true},
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":1`, wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "keys are independent",
+			steps: []step{
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":1`},
+				{key: "k2", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":2`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "key reused for a different body",
+			steps: []step{
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":1`},
+				{key: "k1", body: `{"a":2}`, wantStatus: http.StatusConflict},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "invalid key",
+			steps: []step{
+				{key: "has space", body: `{"a":1}`, wantStatus: http.StatusBadRequest},
+			},
+			wantCalls: 0,
+		},
+		{
+			name:      "failure is not stored",
+			failFirst: true,
+			steps: []step{
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusInternalServerError},
+				{key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCall: `"call":2`},
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := newIdempotencyTest(t, 0)
+			it.fail.Store(tt.failFirst)
+
+			var first *httptest.ResponseRecorder
+
+			for i, s := range tt.steps {
+				w := it.post(s.key, s.body)
+				it.fail.Store(false)
+
+				if w.Code != s.wantStatus {
+					t.Fatalf("step %d: status = %d, want %d: %s", i, w.Code, s.wantStatus, w.Body)
+				}
+				if s.wantCall != "" && !strings.Contains(w.Body.String(), s.wantCall) {
+					t.Errorf("step %d: body = %s, want %s", i, w.Body, s.wantCall)
+				}
+
+				replayed := w.Header().Get("Idempotent-Replayed") == "true"
+				if replayed != s.wantReplayed {
+					t.Errorf("step %d: replayed = %t, want %t", i, replayed, s.wantReplayed)
+				}
+
+				if i == 0 {
+					first = w
+					continue
+				}
+
+				if replayed {
+					if got, want := w.Header().Get("Location"), first.Header().Get("Location"); got != want {
+						t.Errorf("step %d: Location = %q, want %q", i, got, want)
+					}
+					if got := w.Header().Get("X-Request-Only"); got != "" {
+						t.Errorf("step %d: X-Request-Only replayed as %q", i, got)
+					}
+					if w.Body.String() != first.Body.String() {
+						t.Errorf("step %d: body = %s, want %s", i, w.Body, first.Body)
+					}
+				}
+			}
+
+			if got := it.calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	tests := []struct {
+		name string
+
+		// wait is how long the duplicate waits for the first request.
+		wait time.Duration
+
+		// finish lets the first request complete while the duplicate is
+		// still waiting.
+		finish bool
+
+		wantStatus   int
+		wantReplayed bool
+	}{
+		{
+			name:       "gives up with a 409",
+			wait:       100 * time.Millisecond,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:         "gets the response once it is stored",
+			wait:         5 * time.Second,
+			finish:       true,
+			wantStatus:   http.StatusCreated,
+			wantReplayed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := newIdempotencyTest(t, tt.wait)
+			it.block = make(chan struct{})
+
+			done := make(chan *httptest.ResponseRecorder)
+			go func() {
+				done <- it.post("k1", `{"a":1}`)
+			}()
+
+			<-it.started
+
+			if tt.finish {
+				time.AfterFunc(200*time.Millisecond, func() { close(it.block) })
+			}
+
+			w := it.post("k1", `{"a":1}`)
+
+			if !tt.finish {
+				close(it.block)
+			}
+
+			first := <-done
+
+			if first.Code != http.StatusCreated {
+				t.Fatalf("first request: status = %d, want %d", first.Code, http.StatusCreated)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("duplicate: status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			replayed := w.Header().Get("Idempotent-Replayed") == "true"
+			if replayed != tt.wantReplayed {
+				t.Errorf("duplicate: replayed = %t, want %t", replayed, tt.wantReplayed)
+			}
+
+			if w.Code == http.StatusConflict && w.Header().Get("Retry-After") == "" {
+				t.Error("duplicate: 409 without Retry-After")
+			}
+
+			if got := it.calls.Load(); got != 1 {
+				t.Errorf("handler ran %d times, want 1", got)
+			}
+
+			// Once the first request is done, retries are replayed.
+			if w := it.post("k1", `{"a":1}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+				t.Errorf("retry after completion: status = %d, replayed = %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+			}
+		})
+	}
+}
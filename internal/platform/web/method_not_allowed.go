@@ -2,14 +2,29 @@ package web
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
 )
 
 func MethodNotAllowed(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	loc := GetLocalizer(ctx)
+
+	msg := loc.Localize(i18n.Message{
+		Key:  "error.method_not_allowed",
+		Args: i18n.Args{"method": r.Method},
+	})
+
+	w.Header().Set("Content-Language", loc.Language().String())
+
+	if cfg, ok := getProblem(ctx); ok {
+		title := problemTitle(loc, "method_not_allowed", http.StatusMethodNotAllowed)
+		return problemResponse(ctx, w, cfg, http.StatusMethodNotAllowed, "method_not_allowed", title, msg, nil)
+	}
+
 	env := Envelope{
 		"code":    "method_not_allowed",
-		"message": fmt.Sprintf("the %s method is not supported for this resource", r.Method),
+		"message": msg,
 	}
 
 	return Encode(ctx, w, http.StatusMethodNotAllowed, env)
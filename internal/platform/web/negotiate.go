@@ -0,0 +1,224 @@
+package web
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type CompressionConfig struct {
+	// Disabled turns response compression off.
+	Disabled bool
+
+	// MinSize is the smallest body worth compressing. Smaller bodies are
+	// sent as is, since compression would barely shrink them.
+	MinSize int
+}
+
+// DefaultCompression compresses bodies of 1KB and up.
+var DefaultCompression = CompressionConfig{MinSize: 1024}
+
+// negotiation holds what the client asked for in the way of
+// representation, read once per request.
+type negotiation struct {
+	accept   []mediaRange
+	compress bool
+	encoding string
+	minSize  int
+	pretty   bool
+}
+
+func negotiate(r *http.Request, cfg CompressionConfig) *negotiation {
+	n := negotiation{
+		accept:   parseAccept(r.Header.Get("Accept")),
+		compress: !cfg.Disabled,
+		minSize:  cfg.MinSize,
+	}
+
+	if n.compress {
+		n.encoding = acceptedEncoding(r.Header.Get("Accept-Encoding"))
+	}
+
+	n.pretty, _ = strconv.ParseBool(r.URL.Query().Get("pretty"))
+
+	return &n
+}
+
+// choose returns the index of the alternate the client prefers over JSON,
+// or -1 for JSON. JSON wins ties and is the fallback when nothing matches.
+func (n *negotiation) choose(alts []Alternate) int {
+	best, bestQ := -1, n.quality(contentTypeJSON)
+
+	for i, alt := range alts {
+		if q := n.quality(alt.Encoder.ContentType()); q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+
+	return best
+}
+
+func (n *negotiation) quality(contentType string) float64 {
+	if len(n.accept) == 0 {
+		return 0.001
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	typ, sub, _ := strings.Cut(mt, "/")
+
+	q, specificity := 0.0, -1
+	for _, m := range n.accept {
+		var s int
+		switch {
+		case m.typ == typ && m.sub == sub:
+			s = 2
+		case m.typ == typ && m.sub == "*":
+			s = 1
+		case m.typ == "*" && m.sub == "*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			q, specificity = m.q, s
+		}
+	}
+
+	return q
+}
+
+type mediaRange struct {
+	typ, sub string
+	q        float64
+}
+
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+
+	for part := range strings.SplitSeq(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		typ, sub, ok := strings.Cut(mt, "/")
+		if !ok {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{typ: typ, sub: sub, q: parseQ(params["q"])})
+	}
+
+	return ranges
+}
+
+// acceptedEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip when both are equally acceptable.
+func acceptedEncoding(header string) string {
+	qs := map[string]float64{}
+
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q = parseQ(v)
+		}
+
+		qs[name] = q
+	}
+
+	pick := func(name string) float64 {
+		if q, ok := qs[name]; ok {
+			return q
+		}
+
+		return qs["*"]
+	}
+
+	gz, df := pick("gzip"), pick("deflate")
+
+	switch {
+	case gz > 0 && gz >= df:
+		return "gzip"
+	case df > 0:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+func parseQ(s string) float64 {
+	if s == "" {
+		return 1
+	}
+
+	q, err := strconv.ParseFloat(s, 64)
+	if err != nil || q < 0 {
+		return 0
+	}
+
+	return min(q, 1)
+}
+
+// =============================================================================
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	zlibWriters = sync.Pool{New: func() any { return zlib.NewWriter(io.Discard) }}
+)
+
+type resetWriteCloser interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// write sends body, compressed when the client accepts it and the body is
+// large enough to be worth it.
+func write(w http.ResponseWriter, n *negotiation, status int, body []byte) error {
+	h := w.Header()
+
+	// Whether or not this body is compressed, another request for the
+	// same resource might be, so caches must key on Accept-Encoding.
+	if n.compress {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	if n.encoding == "" || len(body) < n.minSize || h.Get("Content-Encoding") != "" {
+		w.WriteHeader(status)
+		_, err := w.Write(body)
+
+		return err
+	}
+
+	pool := &gzipWriters
+	if n.encoding == "deflate" {
+		pool = &zlibWriters
+	}
+
+	zw := pool.Get().(resetWriteCloser)
+	defer pool.Put(zw)
+
+	h.Set("Content-Encoding", n.encoding)
+	h.Del("Content-Length")
+
+	w.WriteHeader(status)
+
+	zw.Reset(w)
+
+	if _, err := zw.Write(body); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agkmw/reddit-clone/internal/platform/errs"
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+)
+
+const problemContentType = "application/problem+json"
+
+// ProblemConfig controls when errors are written as RFC 9457 problem details
+// instead of the legacy {code, message, data} envelope.
+type ProblemConfig struct {
+	Always  bool
+	TypeURI string
+}
+
+type problemField struct {
+	Pointer string `json:"pointer"`
+	Detail  any    `json:"detail"`
+}
+
+func problemResponse(
+	ctx context.Context,
+	w http.ResponseWriter,
+	cfg ProblemConfig,
+	status int,
+	code string,
+	title string,
+	detail string,
+	data errs.ErrorInfo,
+) error {
+	env := Envelope{
+		"type":     problemTypeURI(cfg.TypeURI, code),
+		"title":    title,
+		"status":   status,
+		"detail":   detail,
+		"instance": GetTraceID(ctx),
+	}
+
+	if len(data) > 0 {
+		fields := make([]problemField, 0, len(data))
+		for k, v := range data {
+			fields = append(fields, problemField{Pointer: "#/" + k, Detail: v})
+		}
+
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].Pointer < fields[j].Pointer
+		})
+
+		env["errors"] = fields
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", problemContentType)
+
+	return EncodeWithHeaders(ctx, w, status, env, headers)
+}
+
+func problemTypeURI(base, code string) string {
+	if base == "" {
+		base = "/problems/"
+	}
+
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	return base + strings.ReplaceAll(code, "_", "-")
+}
+
+func problemTitle(loc *i18n.Localizer, code string, status int) string {
+	msg := i18n.Message{
+		Key:      "problem." + code,
+		Fallback: http.StatusText(status),
+	}
+
+	return loc.Localize(msg)
+}
+
+func acceptsProblem(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || mediaType != problemContentType {
+				continue
+			}
+
+			if q, ok := params["q"]; ok {
+				if v, err := strconv.ParseFloat(q, 64); err != nil || v == 0 {
+					continue
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
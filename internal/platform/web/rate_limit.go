@@ -2,67 +2,148 @@ package web
 
 import (
 	"context"
-	"net"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
-	"golang.org/x/time/rate"
+	"github.com/agkmw/reddit-clone/internal/platform/ratelimit"
 )
 
-func RateLimit(
-	enabled bool,
-	rps float64,
-	burst int,
-) Middleware {
-	mid := func(handler Handler) Handler {
-		type client struct {
-			limiter  *rate.Limiter
-			lastSeen time.Time
+// KeyFunc identifies the client a request is counted against. An empty key
+// skips rate limiting for the request.
+type KeyFunc func(ctx context.Context, r *http.Request) string
+
+// KeyByIP counts requests against the resolved client IP. Requests whose
+// client cannot be identified share one bucket.
+func KeyByIP(ctx context.Context, r *http.Request) string {
+	ip := GetClientIP(ctx)
+	if !ip.IsValid() {
+		return "ip:unknown"
+	}
+
+	return "ip:" + ip.String()
+}
+
+// KeyByUser counts requests against the user id returned by userID, falling
+// back to the client IP for anonymous requests.
+func KeyByUser(userID func(ctx context.Context) string) KeyFunc {
+	return func(ctx context.Context, r *http.Request) string {
+		if id := userID(ctx); id != "" {
+			return "user:" + id
 		}
 
-		var (
-			mu      sync.Mutex
-			clients = make(map[string]*client)
-		)
+		return KeyByIP(ctx, r)
+	}
+}
 
-		go func() {
-			time.Sleep(time.Minute)
+// KeyByHeader counts requests against the value of a header such as an API
+// key, falling back to the client IP when it is missing. The value is
+// hashed so that credentials are never stored by the limiter.
+func KeyByHeader(name string) KeyFunc {
+	return func(ctx context.Context, r *http.Request) string {
+		v := r.Header.Get(name)
+		if v == "" {
+			return KeyByIP(ctx, r)
+		}
 
-			mu.Lock()
-			{
-				for ip, client := range clients {
-					if time.Since(client.lastSeen) >= 3*time.Minute {
-						delete(clients, ip)
-					}
-				}
-			}
-			mu.Unlock()
-		}()
+		sum := sha256.Sum256([]byte(v))
+		return "key:" + hex.EncodeToString(sum[:12])
+	}
+}
+
+// =============================================================================
+
+// RateLimitPolicy says how much traffic a client may send.
+type RateLimitPolicy struct {
+	// Limit returns the limit for the request, or false to let it through.
+	// It is called per request so that limits can be reloaded or depend on
+	// who is asking.
+	Limit func(ctx context.Context) (ratelimit.Limit, bool)
+
+	// Key identifies the client. It defaults to KeyByIP.
+	Key KeyFunc
 
+	// PerRoute counts each route pattern separately. Otherwise every route
+	// the policy is attached to shares one budget.
+	PerRoute bool
+
+	// Name separates the keys of this policy from those of other policies
+	// using the same limiter.
+	Name string
+}
+
+// FixedLimit returns a Limit func for a limit that never changes.
+func FixedLimit(limit ratelimit.Limit) func(ctx context.Context) (ratelimit.Limit, bool) {
+	return func(ctx context.Context) (ratelimit.Limit, bool) {
+		return limit, true
+	}
+}
+
+type RateLimitConfig struct {
+	Limiter ratelimit.Limiter
+	Policy  RateLimitPolicy
+
+	// OnReject is called for every rejected request.
+	OnReject func(ctx context.Context)
+
+	// OnError is called when the limiter fails. The request is let through
+	// so that an unavailable store does not take the API down with it.
+	OnError func(ctx context.Context, err error)
+}
+
+// RateLimit rejects requests over the policy's limit with a 429 and reports
+// the client's budget in the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers, plus Retry-After on rejection.
+func RateLimit(cfg RateLimitConfig) Middleware {
+	keyFn := cfg.Policy.Key
+	if keyFn == nil {
+		keyFn = KeyByIP
+	}
+
+	mid := func(handler Handler) Handler {
 		hdl := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-			if enabled {
-				ip, _, err := net.SplitHostPort(r.RemoteAddr)
-				if err != nil {
-					return ServerErrorResponse(ctx, w)
+			limit, ok := cfg.Policy.Limit(ctx)
+			if !ok {
+				return handler(ctx, w, r)
+			}
+
+			key := keyFn(ctx, r)
+			if key == "" {
+				return handler(ctx, w, r)
+			}
+
+			if cfg.Policy.PerRoute {
+				key = r.Method + " " + routePattern(r) + "|" + key
+			}
+			if cfg.Policy.Name != "" {
+				key = cfg.Policy.Name + "|" + key
+			}
+
+			res, err := cfg.Limiter.Allow(ctx, key, limit)
+			if err != nil {
+				if cfg.OnError != nil {
+					cfg.OnError(ctx, err)
 				}
 
-				mu.Lock()
-				{
-					if _, found := clients[ip]; !found {
-						clients[ip] = &client{
-							limiter: rate.NewLimiter(rate.Limit(rps), burst),
-						}
-					}
+				return handler(ctx, w, r)
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", seconds(res.Reset))
 
-					clients[ip].lastSeen = time.Now()
+			if !res.Allowed {
+				h.Set("Retry-After", seconds(max(res.RetryAfter, time.Second)))
 
-					if !clients[ip].limiter.Allow() {
-						mu.Unlock()
-						return RateLimitExceededResponse(ctx, w)
-					}
+				if cfg.OnReject != nil {
+					cfg.OnReject(ctx)
 				}
-				mu.Unlock()
+
+				return RateLimitExceededResponse(ctx, w)
 			}
 
 			return handler(ctx, w, r)
@@ -73,3 +154,8 @@ func RateLimit(
 
 	return mid
 }
+
+// seconds rounds d up to whole seconds, as the rate limit headers expect.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(max(d, 0).Seconds())))
+}
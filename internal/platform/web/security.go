@@ -0,0 +1,107 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type SecurityConfig struct {
+	// HSTSMaxAge is how long browsers keep to HTTPS for this host and its
+	// subdomains. Zero leaves Strict-Transport-Security out.
+	HSTSMaxAge time.Duration
+
+	// ReferrerPolicy defaults to no-referrer.
+	ReferrerPolicy string
+
+	// CSP is the Content-Security-Policy sent with HTML responses. It
+	// defaults to a policy that loads nothing and cannot be framed, as the
+	// API serves no pages of its own.
+	CSP string
+}
+
+// securityHeaders are set on every response before routing, so that not
+// found, method not allowed and preflight responses carry them too.
+type securityHeaders struct {
+	hsts     string
+	referrer string
+	csp      string
+}
+
+func newSecurityHeaders(cfg SecurityConfig) *securityHeaders {
+	s := securityHeaders{
+		referrer: cfg.ReferrerPolicy,
+		csp:      cfg.CSP,
+	}
+
+	if cfg.HSTSMaxAge > 0 {
+		s.hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	if s.referrer == "" {
+		s.referrer = "no-referrer"
+	}
+
+	if s.csp == "" {
+		s.csp = "default-src 'none'; frame-ancestors 'none'"
+	}
+
+	return &s
+}
+
+func (s *securityHeaders) handle(w http.ResponseWriter) http.ResponseWriter {
+	h := w.Header()
+
+	if s.hsts != "" {
+		h.Set("Strict-Transport-Security", s.hsts)
+	}
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Referrer-Policy", s.referrer)
+
+	return &htmlPolicyWriter{ResponseWriter: w, csp: s.csp}
+}
+
+// htmlPolicyWriter adds the Content-Security-Policy once the response turns
+// out to be HTML.
+type htmlPolicyWriter struct {
+	http.ResponseWriter
+
+	csp   string
+	wrote bool
+}
+
+func (w *htmlPolicyWriter) WriteHeader(status int) {
+	if !w.wrote {
+		w.wrote = true
+		w.addPolicy(w.Header().Get("Content-Type"))
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *htmlPolicyWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		// Without a Content-Type the server sniffs one from the body, so
+		// do the same to find out whether it will be HTML.
+		if ct := w.Header().Get("Content-Type"); ct == "" {
+			w.addPolicy(http.DetectContentType(b))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *htmlPolicyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *htmlPolicyWriter) addPolicy(contentType string) {
+	mt, _, _ := mime.ParseMediaType(contentType)
+
+	if mt == "text/html" || mt == "application/xhtml+xml" {
+		w.Header().Set("Content-Security-Policy", w.csp)
+	}
+}
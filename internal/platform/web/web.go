@@ -5,27 +5,42 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/agkmw/reddit-clone/internal/platform/i18n"
+	"github.com/agkmw/reddit-clone/internal/platform/trace"
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 )
 
 type Handler func(ctx context.Context, w http.ResponseWriter, r *http.Request) error
 
 type LogFn func(ctx context.Context, msg string, args ...any)
 
+type MetricsFn func(ctx context.Context, method, route string, status int, took time.Duration)
+
 type App struct {
-	log LogFn
-	mux *chi.Mux
-	mw  []Middleware
+	log     LogFn
+	mux     *chi.Mux
+	mw      []Middleware
+	problem ProblemConfig
+	catalog *i18n.Catalog
+	metrics MetricsFn
+	spans   *trace.Provider
+	ips     *IPResolver
+	cors    *CORS
+	zip     CompressionConfig
+	secure  *securityHeaders
 }
 
 func NewApp(logFn LogFn, mw ...Middleware) *App {
 	mux := chi.NewMux()
 
 	app := App{
-		log: logFn,
-		mux: mux,
-		mw:  mw,
+		log:     logFn,
+		mux:     mux,
+		mw:      mw,
+		catalog: i18n.Default(),
+		ips:     &IPResolver{},
+		zip:     DefaultCompression,
+		secure:  newSecurityHeaders(SecurityConfig{}),
 	}
 
 	app.NotFound(NotFound)
@@ -67,6 +82,12 @@ func (app *App) HandlerFuncWithMid(
 }
 
 func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w = app.secure.handle(w)
+
+	if app.cors != nil && app.cors.handle(w, r) {
+		return
+	}
+
 	app.mux.ServeHTTP(w, r)
 }
 
@@ -78,20 +99,100 @@ func (app *App) NotFound(handler Handler) {
 	app.mux.NotFound(app.handle(handler))
 }
 
+func (app *App) ProblemDetails(cfg ProblemConfig) {
+	app.problem = cfg
+}
+
+func (app *App) Localization(catalog *i18n.Catalog) {
+	app.catalog = catalog
+}
+
+func (app *App) Metrics(fn MetricsFn) {
+	app.metrics = fn
+}
+
+func (app *App) Tracing(p *trace.Provider) {
+	app.spans = p
+}
+
+func (app *App) ClientIP(res *IPResolver) {
+	app.ips = res
+}
+
+func (app *App) CORS(c *CORS) {
+	app.cors = c
+}
+
+func (app *App) Compression(cfg CompressionConfig) {
+	app.zip = cfg
+}
+
+func (app *App) Security(cfg SecurityConfig) {
+	app.secure = newSecurityHeaders(cfg)
+}
+
 func (app *App) handle(handler Handler) http.HandlerFunc {
 	h := func(w http.ResponseWriter, r *http.Request) {
+		ctx := trace.Extract(r.Context(), r.Header)
+
+		ctx, span := app.spans.Start(ctx, r.Method, trace.SpanKindServer)
+		defer span.End()
+
+		sc := span.SpanContext()
+
 		tracer := Tracer{
-			Now:     time.Now(),
-			TraceID: uuid.New().String(),
+			Now:      time.Now(),
+			TraceID:  sc.TraceID.String(),
+			SpanID:   sc.SpanID.String(),
+			ClientIP: app.ips.Resolve(r),
 		}
 
-		ctx := setTracer(r.Context(), &tracer)
+		ctx = setTracer(ctx, &tracer)
+
+		trace.Inject(ctx, w.Header())
+
+		lang := app.catalog.Match(r.Header.Get("Accept-Language"))
+		ctx = setLocalizer(ctx, app.catalog.Localizer(lang))
+
+		ctx = setNegotiation(ctx, negotiate(r, app.zip))
+
+		if app.problem.Always || acceptsProblem(r) {
+			ctx = setProblem(ctx, app.problem)
+		}
 
 		err := handler(ctx, w, r)
 		if err != nil {
 			app.log(ctx, "unexpected error occurred", "error", err)
 		}
+
+		route := routePattern(r)
+
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(
+			"http.request.method", r.Method,
+			"http.route", route,
+			"url.path", r.URL.Path,
+			"client.address", tracer.ClientIP.String(),
+			"http.response.status_code", tracer.StatusCode,
+		)
+
+		if tracer.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(trace.StatusError, http.StatusText(tracer.StatusCode))
+		}
+
+		if app.metrics != nil {
+			app.metrics(ctx, r.Method, route, tracer.StatusCode, time.Since(tracer.Now))
+		}
 	}
 
 	return h
 }
+
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.RoutePattern() == "" {
+		return "unmatched"
+	}
+
+	return rctx.RoutePattern()
+}
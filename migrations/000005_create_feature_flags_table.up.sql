@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name        text PRIMARY KEY,
+    description text    NOT NULL DEFAULT '',
+    enabled     bool    NOT NULL DEFAULT false,
+    rollout     integer NOT NULL DEFAULT 100 CHECK (rollout BETWEEN 0 AND 100),
+
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    flag_name text NOT NULL REFERENCES feature_flags(name) ON DELETE CASCADE,
+
+    kind    text NOT NULL CHECK (kind IN ('user', 'role')),
+    subject text NOT NULL,
+    enabled bool NOT NULL,
+
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (flag_name, kind, subject)
+);